@@ -0,0 +1,152 @@
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+	"github.com/Andrei-Barwood/gcpsec/internal/report"
+)
+
+const Version = 2
+
+// legacyVersion baselines predate model.Fingerprint and identify findings by
+// a hash of rule id, project and resource only. They are still matched that
+// way; run gcpsec baseline create again to upgrade one.
+const legacyVersion = 1
+
+// Entry is the part of a finding a baseline keeps: enough to match it in a
+// later scan and to describe it once it has been fixed.
+type Entry struct {
+	Fingerprint string         `json:"fingerprint"`
+	ID          string         `json:"id"`
+	Severity    model.Severity `json:"severity"`
+	Project     string         `json:"project,omitempty"`
+	Resource    string         `json:"resource,omitempty"`
+	Summary     string         `json:"summary,omitempty"`
+}
+
+type Baseline struct {
+	Version     int       `json:"baseline_version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Findings    []Entry   `json:"findings"`
+}
+
+// Fingerprint identifies a finding across scans. Scans written before
+// findings carried a fingerprint get one computed from their content.
+func Fingerprint(f model.Finding) string {
+	if f.Fingerprint != "" {
+		return f.Fingerprint
+	}
+	return model.Fingerprint(f)
+}
+
+func legacyFingerprint(f model.Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.ID, f.Project, f.Resource}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Create builds a baseline from the findings of a scan, one entry per
+// fingerprint, sorted so the file diffs cleanly.
+func Create(result model.ScanResult) Baseline {
+	b := Baseline{Version: Version, GeneratedAt: result.GeneratedAt, Findings: []Entry{}}
+	seen := map[string]bool{}
+	for _, f := range result.Findings {
+		fp := Fingerprint(f)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		b.Findings = append(b.Findings, Entry{
+			Fingerprint: fp,
+			ID:          f.ID,
+			Severity:    f.Severity,
+			Project:     f.Project,
+			Resource:    f.Resource,
+			Summary:     f.Summary,
+		})
+	}
+	sort.Slice(b.Findings, func(i, j int) bool { return b.Findings[i].Fingerprint < b.Findings[j].Fingerprint })
+	return b
+}
+
+// Load reads a baseline file. A raw scan JSON is accepted too and turned
+// into a baseline on the fly.
+func Load(path string) (Baseline, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(buf, &b); err != nil {
+		return Baseline{}, err
+	}
+	if b.Version > Version {
+		return Baseline{}, fmt.Errorf("baseline version %d is newer than supported version %d", b.Version, Version)
+	}
+	if b.Version != 0 {
+		return b, nil
+	}
+	scan, err := report.DecodeScan(buf)
+	if err != nil {
+		return Baseline{}, err
+	}
+	return Create(scan), nil
+}
+
+// Apply marks every finding in result as new or existing and lists the
+// baseline findings that no longer appear in result.Fixed. A baseline
+// finding whose check did not complete in result is not counted as fixed;
+// a note records how many were left out.
+func Apply(result *model.ScanResult, b Baseline) {
+	known := make(map[string]bool, len(b.Findings))
+	for _, e := range b.Findings {
+		known[e.Fingerprint] = true
+	}
+
+	match := Fingerprint
+	if b.Version == legacyVersion {
+		match = legacyFingerprint
+	}
+
+	present := map[string]bool{}
+	for i := range result.Findings {
+		fp := match(result.Findings[i])
+		present[fp] = true
+		if known[fp] {
+			result.Findings[i].BaselineState = model.BaselineExisting
+		} else {
+			result.Findings[i].BaselineState = model.BaselineNew
+		}
+	}
+
+	result.Fixed = nil
+	unevaluated := 0
+	for _, e := range b.Findings {
+		if present[e.Fingerprint] {
+			continue
+		}
+		f := model.Finding{
+			ID:            e.ID,
+			Severity:      e.Severity,
+			Summary:       e.Summary,
+			Project:       e.Project,
+			Resource:      e.Resource,
+			BaselineState: model.BaselineFixed,
+		}
+		if !result.Evaluated(f) {
+			unevaluated++
+			continue
+		}
+		result.Fixed = append(result.Fixed, f)
+	}
+	if unevaluated > 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf("%d baseline finding(s) not evaluated: their check did not complete in this scan", unevaluated))
+	}
+}
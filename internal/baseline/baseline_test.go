@@ -0,0 +1,112 @@
+package baseline
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestApplyMarksNewExistingAndFixed(t *testing.T) {
+	old := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "maps", Severity: model.SeverityHigh, Summary: "API key has no restrictions"},
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/1", Severity: model.SeverityHigh},
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/1", Severity: model.SeverityHigh},
+	}}
+	b := Create(old)
+	if len(b.Findings) != 2 || b.Version != Version {
+		t.Fatalf("expected two deduplicated entries, got %+v", b)
+	}
+
+	current := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/1", Severity: model.SeverityHigh},
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/2", Severity: model.SeverityHigh},
+	}}
+	Apply(&current, b)
+
+	if got := current.Findings[0].BaselineState; got != model.BaselineExisting {
+		t.Fatalf("expected existing, got %q", got)
+	}
+	if got := current.Findings[1].BaselineState; got != model.BaselineNew {
+		t.Fatalf("expected new, got %q", got)
+	}
+	if len(current.Fixed) != 1 || current.Fixed[0].Resource != "maps" || current.Fixed[0].BaselineState != model.BaselineFixed {
+		t.Fatalf("expected the api key finding to be fixed, got %+v", current.Fixed)
+	}
+}
+
+func TestApplyLeavesFindingsOfIncompleteChecksOutOfFixed(t *testing.T) {
+	b := Create(model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "keys/maps", Severity: model.SeverityHigh},
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/1", Severity: model.SeverityHigh},
+	}})
+
+	current := model.ScanResult{Findings: []model.Finding{}, Executions: []model.CheckExecution{
+		{Check: "api-keys", Project: "demo", Status: model.CheckErrored, FindingIDs: []string{"gcp.api_key.unrestricted"}},
+		{Check: "service-account-keys", Project: "demo", Status: model.CheckPassed, FindingIDs: []string{"gcp.sa_key.no_expiry"}},
+	}}
+	Apply(&current, b)
+
+	if len(current.Fixed) != 1 || current.Fixed[0].ID != "gcp.sa_key.no_expiry" {
+		t.Fatalf("expected only the service account key to be fixed, got %+v", current.Fixed)
+	}
+	if len(current.Notes) != 1 {
+		t.Fatalf("expected a note about the unevaluated finding, got %v", current.Notes)
+	}
+}
+
+func TestLoadAcceptsBaselineAndScanFiles(t *testing.T) {
+	scan := model.ScanResult{Findings: []model.Finding{{ID: "x", Resource: "r"}}}
+	dir := t.TempDir()
+	for name, v := range map[string]any{"baseline.json": Create(scan), "scan.json": scan} {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		b, err := Load(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(b.Findings) != 1 || b.Findings[0].Fingerprint != Fingerprint(scan.Findings[0]) {
+			t.Fatalf("%s: unexpected baseline %+v", name, b)
+		}
+	}
+}
+
+func TestLegacyBaselinesStillMatch(t *testing.T) {
+	key := model.Finding{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "keys/abc", Severity: model.SeverityHigh, Metadata: map[string]string{"key_name": "projects/demo/locations/global/keys/abc"}}
+	legacy := Baseline{Version: legacyVersion, Findings: []Entry{
+		{Fingerprint: legacyFingerprint(key), ID: key.ID, Project: key.Project, Resource: key.Resource},
+	}}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	buf, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	current := model.ScanResult{Findings: []model.Finding{key}}
+	Apply(&current, b)
+	if got := current.Findings[0].BaselineState; got != model.BaselineExisting || len(current.Fixed) != 0 {
+		t.Fatalf("expected the legacy entry to match, got %q with fixed %+v", got, current.Fixed)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"baseline_version":99,"findings":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected a newer baseline version to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package baseline
+
+import (
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+// Change is a finding present in both scans whose tracked fields differ.
+type Change struct {
+	Fingerprint string        `json:"fingerprint"`
+	Fields      []string      `json:"fields"`
+	Old         model.Finding `json:"old"`
+	New         model.Finding `json:"new"`
+}
+
+type Diff struct {
+	OldGeneratedAt time.Time       `json:"old_generated_at"`
+	NewGeneratedAt time.Time       `json:"new_generated_at"`
+	Added          []model.Finding `json:"added"`
+	Resolved       []model.Finding `json:"resolved"`
+	NotEvaluated   []model.Finding `json:"not_evaluated"`
+	Changed        []Change        `json:"changed"`
+	Unchanged      int             `json:"unchanged"`
+}
+
+// Compare matches the findings of two scans by fingerprint. Added and
+// changed findings keep the order of the new scan, resolved ones the order
+// of the old scan. An old finding missing from the new scan is resolved only
+// when its check passed or failed there; otherwise it is not evaluated.
+func Compare(old, current model.ScanResult) Diff {
+	d := Diff{
+		OldGeneratedAt: old.GeneratedAt,
+		NewGeneratedAt: current.GeneratedAt,
+		Added:          []model.Finding{},
+		Resolved:       []model.Finding{},
+		NotEvaluated:   []model.Finding{},
+		Changed:        []Change{},
+	}
+
+	before := map[string]model.Finding{}
+	for _, f := range old.Findings {
+		if _, dup := before[Fingerprint(f)]; !dup {
+			before[Fingerprint(f)] = f
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, f := range current.Findings {
+		fp := Fingerprint(f)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		prev, ok := before[fp]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, f)
+		default:
+			if fields := changedFields(prev, f); len(fields) > 0 {
+				d.Changed = append(d.Changed, Change{Fingerprint: fp, Fields: fields, Old: prev, New: f})
+			} else {
+				d.Unchanged++
+			}
+		}
+	}
+
+	for _, f := range old.Findings {
+		fp := Fingerprint(f)
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		if current.Evaluated(f) {
+			d.Resolved = append(d.Resolved, f)
+		} else {
+			d.NotEvaluated = append(d.NotEvaluated, f)
+		}
+	}
+	return d
+}
+
+func changedFields(old, current model.Finding) []string {
+	var fields []string
+	if old.Severity != current.Severity {
+		fields = append(fields, "severity")
+	}
+	if old.Summary != current.Summary {
+		fields = append(fields, "summary")
+	}
+	if old.Metadata["status"] != current.Metadata["status"] {
+		fields = append(fields, "status")
+	}
+	return fields
+}
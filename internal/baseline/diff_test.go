@@ -0,0 +1,54 @@
+package baseline
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestCompareMatchesByFingerprint(t *testing.T) {
+	old := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.api_key.unrestricted", Resource: "maps", Severity: model.SeverityHigh},
+		{ID: "gcp.org_policy.compute.requireOsLogin", Resource: "demo", Severity: model.SeverityMedium, Metadata: map[string]string{"constraint": "constraints/compute.requireOsLogin", "status": "not_set"}},
+		{ID: "gcp.sa_key.stale_review", Resource: "keys/1", Severity: model.SeverityMedium, Description: "31 days old"},
+	}}
+	current := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.sa_key.stale_review", Resource: "keys/1", Severity: model.SeverityMedium, Description: "32 days old"},
+		{ID: "gcp.org_policy.compute.requireOsLogin", Resource: "demo", Severity: model.SeverityHigh, Metadata: map[string]string{"constraint": "constraints/compute.requireOsLogin", "status": "overridden"}},
+		{ID: "gcp.sa_key.no_expiry", Resource: "keys/2", Severity: model.SeverityHigh},
+	}}
+
+	d := Compare(old, current)
+	if len(d.Added) != 1 || d.Added[0].Resource != "keys/2" {
+		t.Fatalf("unexpected added: %+v", d.Added)
+	}
+	if len(d.Resolved) != 1 || d.Resolved[0].ID != "gcp.api_key.unrestricted" {
+		t.Fatalf("unexpected resolved: %+v", d.Resolved)
+	}
+	if len(d.Changed) != 1 || !reflect.DeepEqual(d.Changed[0].Fields, []string{"severity", "status"}) {
+		t.Fatalf("unexpected changed: %+v", d.Changed)
+	}
+	if d.Unchanged != 1 {
+		t.Fatalf("expected the stale key to be unchanged, got %d", d.Unchanged)
+	}
+}
+
+func TestCompareKeepsFindingsOfIncompleteChecksOutOfResolved(t *testing.T) {
+	old := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "keys/maps", Severity: model.SeverityHigh},
+		{ID: "gcp.sa_key.no_expiry", Project: "demo", Resource: "keys/1", Severity: model.SeverityHigh},
+	}}
+	current := model.ScanResult{Findings: []model.Finding{}, Executions: []model.CheckExecution{
+		{Check: "api-keys", Project: "demo", Status: model.CheckSkipped, FindingIDs: []string{"gcp.api_key.unrestricted"}},
+		{Check: "service-account-keys", Project: "demo", Status: model.CheckPassed, FindingIDs: []string{"gcp.sa_key.no_expiry"}},
+	}}
+
+	d := Compare(old, current)
+	if len(d.Resolved) != 1 || d.Resolved[0].ID != "gcp.sa_key.no_expiry" {
+		t.Fatalf("expected only the service account key to be resolved, got %+v", d.Resolved)
+	}
+	if len(d.NotEvaluated) != 1 || d.NotEvaluated[0].ID != "gcp.api_key.unrestricted" {
+		t.Fatalf("expected the api key to be not evaluated, got %+v", d.NotEvaluated)
+	}
+}
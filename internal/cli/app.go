@@ -6,18 +6,26 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	iofs "io/fs"
 	"os"
 	"sort"
 	"strings"
 
+	"github.com/Andrei-Barwood/gcpsec/internal/baseline"
+	"github.com/Andrei-Barwood/gcpsec/internal/config"
 	"github.com/Andrei-Barwood/gcpsec/internal/execx"
 	"github.com/Andrei-Barwood/gcpsec/internal/format"
+	"github.com/Andrei-Barwood/gcpsec/internal/history"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 	"github.com/Andrei-Barwood/gcpsec/internal/report"
 	"github.com/Andrei-Barwood/gcpsec/internal/scanner"
 )
 
-const defaultScanPath = ".gcpsec/scan.json"
+const (
+	defaultScanPath     = ".gcpsec/scan.json"
+	defaultConfigPath   = ".gcpsec/config.json"
+	defaultBaselinePath = ".gcpsec/baseline.json"
+)
 
 func Run(ctx context.Context, args []string) int {
 	if len(args) == 0 {
@@ -38,6 +46,20 @@ func Run(ctx context.Context, args []string) int {
 		err = runReport(cmdArgs)
 	case "enforce":
 		err = runEnforce(ctx, cmdArgs)
+	case "checks":
+		err = runChecks(cmdArgs)
+	case "doctor":
+		err = runDoctor(ctx, cmdArgs)
+	case "baseline":
+		err = runBaseline(cmdArgs)
+	case "diff":
+		err = runDiff(cmdArgs)
+	case "trend":
+		err = runTrend(cmdArgs)
+	case "validate":
+		err = runValidate(cmdArgs)
+	case "merge":
+		err = runMerge(cmdArgs)
 	case "help", "-h", "--help":
 		printRootUsage(os.Stdout)
 		return 0
@@ -47,11 +69,11 @@ func Run(ctx context.Context, args []string) int {
 		return 2
 	}
 
-	if err != nil {
+	code := exitCode(err)
+	if code != ExitOK {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return 1
 	}
-	return 0
+	return code
 }
 
 func runScan(ctx context.Context, args []string) error {
@@ -59,25 +81,114 @@ func runScan(ctx context.Context, args []string) error {
 	fs.SetOutput(os.Stderr)
 
 	project := fs.String("project", "", "Google Cloud project id")
+	organization := fs.String("organization", "", "Scan every project under this organization id")
+	folder := fs.String("folder", "", "Scan every project under this folder id")
+	parallelism := fs.Int("parallelism", 4, "Projects scanned concurrently with --organization/--folder")
+	concurrency := fs.Int("concurrency", 8, "Checks and per-account API calls run concurrently")
+	rateLimit := fs.Float64("rate-limit", 10, "Maximum GCP API requests per second (0 disables)")
+	selFlags := addSelectionFlags(fs)
+	assetExport := fs.String("asset-export", "", "Scan offline from a Cloud Asset Inventory export (JSON lines)")
+	backend := fs.String("backend", scanner.BackendGCloud, "Remote API backend: gcloud|rest")
+	checkIDs := fs.String("checks", "", "Comma-separated check ids to run (default all; see gcpsec checks list)")
+	skipCheckIDs := fs.String("skip-checks", "", "Comma-separated check ids to skip")
+	recordDir := fs.String("record", "", "Record gcloud invocations into a cassette in this directory")
+	replayDir := fs.String("replay", "", "Replay gcloud invocations from a cassette in this directory instead of running gcloud")
 	repoPath := fs.String("repo", ".", "Repository path to inspect")
 	inactiveDays := fs.Int("inactive-days", 30, "Days threshold for stale key review")
 	outPath := fs.String("out", defaultScanPath, "Path to store raw scan JSON")
+	historyDir := fs.String("history", history.DefaultDir, "Directory keeping a copy of every scan for gcpsec trend (empty disables)")
 	stdoutFormat := fs.String("stdout-format", "summary", "Output format: summary|json|markdown")
+	configPath := fs.String("config", defaultConfigPath, "Path to gcpsec config JSON")
+	gate := addGateFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if err := gate.validate(); err != nil {
+		return usageError(err)
+	}
+
+	scopes := 0
+	for _, v := range []string{*project, *organization, *folder} {
+		if strings.TrimSpace(v) != "" {
+			scopes++
+		}
+	}
+	if scopes > 1 {
+		return usageError(errors.New("use only one of --project, --organization or --folder"))
+	}
+	selection, err := selFlags.selection()
+	if err != nil {
+		return usageError(err)
+	}
+	if strings.TrimSpace(*assetExport) != "" && (strings.TrimSpace(*organization) != "" || strings.TrimSpace(*folder) != "") {
+		return usageError(errors.New("--asset-export cannot be combined with --organization or --folder"))
+	}
+	switch *backend {
+	case scanner.BackendGCloud, scanner.BackendREST:
+	default:
+		return usageError(fmt.Errorf("invalid backend: %s", *backend))
+	}
+	onlyChecks, skipChecks := splitList(*checkIDs), splitList(*skipCheckIDs)
+	if err := scanner.ValidateCheckIDs(append(append([]string(nil), onlyChecks...), skipChecks...)); err != nil {
+		return usageError(err)
+	}
+	if *recordDir != "" && *replayDir != "" {
+		return usageError(errors.New("use only one of --record or --replay"))
+	}
+	if (*recordDir != "" || *replayDir != "") && *backend != scanner.BackendGCloud {
+		return usageError(errors.New("--record and --replay require --backend=gcloud"))
+	}
+	if selFlags.filtersProjects() && strings.TrimSpace(*organization) == "" && strings.TrimSpace(*folder) == "" && strings.TrimSpace(*assetExport) == "" {
+		return usageError(errors.New("project filters require --organization, --folder or --asset-export"))
+	}
+
+	cfg, err := loadConfig(fs, *configPath)
+	if err != nil {
+		return err
+	}
+	orgPolicies, err := scanner.MergeOrgPolicyBaseline(scanner.DefaultOrgPolicyBaseline(), cfg.OrgPolicies)
+	if err != nil {
+		return err
+	}
+
+	var runner execx.Runner = execx.OSRunner{}
+	switch {
+	case *recordDir != "":
+		runner, err = execx.NewRecordingRunner(runner, *recordDir)
+	case *replayDir != "":
+		runner, err = execx.NewReplayRunner(*replayDir)
+	}
+	if err != nil {
 		return err
 	}
 
 	s := scanner.New(scanner.Options{
-		Project:      strings.TrimSpace(*project),
-		RepoPath:     strings.TrimSpace(*repoPath),
-		InactiveDays: *inactiveDays,
+		Backend:               *backend,
+		Runner:                runner,
+		Project:               strings.TrimSpace(*project),
+		Organization:          strings.TrimSpace(*organization),
+		Folder:                strings.TrimSpace(*folder),
+		Parallelism:           *parallelism,
+		Concurrency:           *concurrency,
+		RateLimit:             *rateLimit,
+		Checks:                onlyChecks,
+		SkipChecks:            skipChecks,
+		Selection:             selection,
+		AssetExport:           strings.TrimSpace(*assetExport),
+		RepoPath:              strings.TrimSpace(*repoPath),
+		InactiveDays:          *inactiveDays,
+		AllowedContactDomains: cfg.EssentialContacts.AllowedDomains,
+		OrgPolicyBaseline:     orgPolicies,
 	})
 
 	result, err := s.Scan(ctx)
 	if err != nil {
 		return err
 	}
+	if err := gate.applyBaseline(&result); err != nil {
+		return err
+	}
 
 	jsonBytes, err := format.JSON(result)
 	if err != nil {
@@ -89,6 +200,11 @@ func runScan(ctx context.Context, args []string) error {
 			return err
 		}
 	}
+	if *historyDir != "" {
+		if _, err := history.Save(*historyDir, result); err != nil {
+			return err
+		}
+	}
 
 	switch strings.ToLower(strings.TrimSpace(*stdoutFormat)) {
 	case "summary":
@@ -102,24 +218,392 @@ func runScan(ctx context.Context, args []string) error {
 		}
 		_, err = os.Stdout.Write(md)
 	default:
-		return fmt.Errorf("invalid stdout-format: %s", *stdoutFormat)
+		return usageError(fmt.Errorf("invalid stdout-format: %s", *stdoutFormat))
+	}
+	if err != nil {
+		return err
 	}
+	return gate.check(result)
+}
+
+func loadConfig(fs *flag.FlagSet, path string) (config.Config, error) {
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicit = true
+		}
+	})
+
+	cfg, err := config.Load(path)
+	if errors.Is(err, iofs.ErrNotExist) && !explicit {
+		return config.Config{}, nil
+	}
+	return cfg, err
+}
+
+func runChecks(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return errors.New("usage: gcpsec checks list [--format table|json]")
+	}
+
+	fs := flag.NewFlagSet("checks list", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	outputFormat := fs.String("format", "table", "Output format: table|json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(err)
+	}
+
+	var infos []scanner.CheckInfo
+	for _, c := range scanner.Checks() {
+		infos = append(infos, c.Info())
+	}
+
+	switch strings.ToLower(strings.TrimSpace(*outputFormat)) {
+	case "table":
+		_, err := os.Stdout.WriteString(renderChecksTable(infos))
+		return err
+	case "json":
+		payload, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(payload, '\n'))
+		return err
+	default:
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
+	}
+}
+
+func runDoctor(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	project := fs.String("project", "", "Google Cloud project id")
+	organization := fs.String("organization", "", "Check permissions on this organization id")
+	folder := fs.String("folder", "", "Check permissions on this folder id")
+	backend := fs.String("backend", scanner.BackendGCloud, "Remote API backend: gcloud|rest")
+	checkIDs := fs.String("checks", "", "Comma-separated check ids to consider (default all)")
+	skipCheckIDs := fs.String("skip-checks", "", "Comma-separated check ids to skip")
+	outputFormat := fs.String("format", "table", "Output format: table|json")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	switch *backend {
+	case scanner.BackendGCloud, scanner.BackendREST:
+	default:
+		return usageError(fmt.Errorf("invalid backend: %s", *backend))
+	}
+	onlyChecks, skipChecks := splitList(*checkIDs), splitList(*skipCheckIDs)
+	if err := scanner.ValidateCheckIDs(append(append([]string(nil), onlyChecks...), skipChecks...)); err != nil {
+		return usageError(err)
+	}
+
+	s := scanner.New(scanner.Options{
+		Backend:      *backend,
+		Project:      strings.TrimSpace(*project),
+		Organization: strings.TrimSpace(*organization),
+		Folder:       strings.TrimSpace(*folder),
+		Checks:       onlyChecks,
+		SkipChecks:   skipChecks,
+	})
+	pre := s.Preflight(ctx)
+
+	switch strings.ToLower(strings.TrimSpace(*outputFormat)) {
+	case "table":
+		if _, err := os.Stdout.WriteString(renderPreflight(pre)); err != nil {
+			return err
+		}
+	case "json":
+		payload, err := json.MarshalIndent(pre, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(append(payload, '\n')); err != nil {
+			return err
+		}
+	default:
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
+	}
+
+	if !pre.OK() {
+		return fmt.Errorf("doctor found %d problem(s)", len(pre.Problems))
+	}
+	return nil
+}
+
+func runBaseline(args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return usageError(errors.New("usage: gcpsec baseline create [--from scan.json] [--out baseline.json]"))
+	}
+
+	fs := flag.NewFlagSet("baseline create", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	from := fs.String("from", defaultScanPath, "Input scan JSON file")
+	out := fs.String("out", defaultBaselinePath, "Path to write the baseline")
+	if err := fs.Parse(args[1:]); err != nil {
+		return usageError(err)
+	}
+
+	scan, err := report.LoadScan(*from)
+	if err != nil {
+		return err
+	}
+	b := baseline.Create(scan)
+	payload, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := report.Save(*out, append(payload, '\n')); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "baseline with %d finding(s) saved to %s\n", len(b.Findings), *out)
+	return nil
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	oldPath := fs.String("old", "", "Earlier scan JSON file")
+	newPath := fs.String("new", "", "Later scan JSON file")
+	out := fs.String("out", "", "Optional output path")
+	outputFormat := fs.String("format", "table", "Output format: table|json|markdown")
+	failOn := fs.String("fail-on", string(model.SeverityHigh), "Exit 3 when an added finding is at or above this severity (none disables)")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if *oldPath == "" || *newPath == "" {
+		return usageError(errors.New("usage: gcpsec diff --old a.json --new b.json"))
+	}
+	var threshold model.Severity
+	if *failOn != "none" && *failOn != "" {
+		var err error
+		if threshold, err = model.ParseSeverity(*failOn); err != nil {
+			return usageError(err)
+		}
+	}
+
+	oldScan, err := report.LoadScan(*oldPath)
+	if err != nil {
+		return err
+	}
+	newScan, err := report.LoadScan(*newPath)
+	if err != nil {
+		return err
+	}
+	d := baseline.Compare(oldScan, newScan)
+
+	var payload []byte
+	switch strings.ToLower(strings.TrimSpace(*outputFormat)) {
+	case "table":
+		payload = []byte(renderDiffTable(d))
+	case "json":
+		payload, err = json.MarshalIndent(d, "", "  ")
+		payload = append(payload, '\n')
+	case "markdown", "md":
+		payload, err = format.DiffMarkdown(d)
+	default:
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		if err := report.Save(*out, payload); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stdout.Write(payload); err != nil {
+		return err
+	}
+
+	if threshold != "" {
+		if n := countAtLeast(d.Added, threshold); n > 0 {
+			return &exitError{code: ExitFindings, err: fmt.Errorf("%d new finding(s) at or above %s", n, threshold)}
+		}
+	}
+	return nil
+}
+
+func runTrend(args []string) error {
+	fs := flag.NewFlagSet("trend", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	dir := fs.String("dir", history.DefaultDir, "History directory written by gcpsec scan")
+	project := fs.String("project", "", "Only scans of this project")
+	organization := fs.String("organization", "", "Only scans of this organization")
+	folder := fs.String("folder", "", "Only scans of this folder")
+	out := fs.String("out", "", "Optional output path")
+	outputFormat := fs.String("format", "table", "Output format: table|csv|markdown|json")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	var scope string
+	for _, s := range []struct{ prefix, value string }{
+		{"projects/", *project},
+		{"organizations/", *organization},
+		{"folders/", *folder},
+	} {
+		if v := strings.TrimSpace(s.value); v != "" {
+			if scope != "" {
+				return usageError(errors.New("use only one of --project, --organization or --folder"))
+			}
+			scope = s.prefix + v
+		}
+	}
+
+	scans, err := history.Load(*dir, scope)
+	if err != nil {
+		return err
+	}
+	if len(scans) == 0 {
+		return fmt.Errorf("no scans found in %s", *dir)
+	}
+	if scope == "" {
+		// Mixing scopes would report findings as fixed whenever another
+		// project was scanned, so default to the most recent scan's scope.
+		scope = history.Scope(scans[len(scans)-1])
+		kept := scans[:0]
+		for _, scan := range scans {
+			if history.Scope(scan) == scope {
+				kept = append(kept, scan)
+			}
+		}
+		scans = kept
+	}
+	t := history.Compute(scope, scans)
+
+	var payload []byte
+	switch strings.ToLower(strings.TrimSpace(*outputFormat)) {
+	case "table":
+		payload = []byte(renderTrendTable(t))
+	case "csv":
+		payload, err = format.TrendCSV(t)
+	case "json":
+		payload, err = json.MarshalIndent(t, "", "  ")
+		payload = append(payload, '\n')
+	case "markdown", "md":
+		payload, err = format.TrendMarkdown(t)
+	default:
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
+	}
+	if err != nil {
+		return err
+	}
+
+	if *out != "" {
+		if err := report.Save(*out, payload); err != nil {
+			return err
+		}
+	}
+	_, err = os.Stdout.Write(payload)
 	return err
 }
 
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	printSchema := fs.Bool("print-schema", false, "Print the scan JSON Schema and exit")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+
+	if *printSchema {
+		schema, err := model.ScanSchemaJSON()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(schema)
+		return err
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{defaultScanPath}
+	}
+	failed := 0
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		version, problems, err := report.Validate(buf)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "%s: %v\n", path, err)
+			continue
+		}
+		if len(problems) > 0 {
+			failed++
+			fmt.Fprintf(os.Stdout, "%s: %d problem(s)\n", path, len(problems))
+			for _, p := range problems {
+				fmt.Fprintf(os.Stdout, "  - %s\n", p)
+			}
+			continue
+		}
+		if version < model.SchemaVersion {
+			fmt.Fprintf(os.Stdout, "%s: ok (schema v%d, migrated to v%d)\n", path, version, model.SchemaVersion)
+		} else {
+			fmt.Fprintf(os.Stdout, "%s: ok (schema v%d)\n", path, version)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d file(s) failed validation", failed, len(paths))
+	}
+	return nil
+}
+
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	from := addFromFlag(fs)
+	out := fs.String("out", "", "Path to write the merged scan JSON (default stdout)")
+
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if len(from.values) < 2 {
+		return usageError(errors.New("usage: gcpsec merge --from a.json --from b.json [--out merged.json]"))
+	}
+
+	scan, err := report.LoadScans(from.paths())
+	if err != nil {
+		return err
+	}
+	payload, err := format.JSON(scan)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(payload)
+		return err
+	}
+	if err := report.Save(*out, payload); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "merged %d scan(s) into %d finding(s), saved to %s\n", len(from.values), len(scan.Findings), *out)
+	return nil
+}
+
 func runRecommend(args []string) error {
 	fs := flag.NewFlagSet("recommend", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 
-	from := fs.String("from", defaultScanPath, "Input scan JSON file")
+	from := addFromFlag(fs)
 	out := fs.String("out", "", "Optional output path")
 	outputFormat := fs.String("format", "table", "Output format: table|json")
 
 	if err := fs.Parse(args); err != nil {
-		return err
+		return usageError(err)
 	}
 
-	scan, err := report.LoadScan(*from)
+	scan, err := report.LoadScans(from.paths())
 	if err != nil {
 		return err
 	}
@@ -135,7 +619,7 @@ func runRecommend(args []string) error {
 			return err
 		}
 	default:
-		return fmt.Errorf("invalid format: %s", *outputFormat)
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
 	}
 
 	if *out != "" {
@@ -152,18 +636,25 @@ func runReport(args []string) error {
 	fs := flag.NewFlagSet("report", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 
-	from := fs.String("from", defaultScanPath, "Input scan JSON file")
+	from := addFromFlag(fs)
 	out := fs.String("out", "", "Optional output path")
 	outputFormat := fs.String("format", "markdown", "Output format: json|markdown|sarif")
+	gate := addGateFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
-		return err
+		return usageError(err)
+	}
+	if err := gate.validate(); err != nil {
+		return usageError(err)
 	}
 
-	scan, err := report.LoadScan(*from)
+	scan, err := report.LoadScans(from.paths())
 	if err != nil {
 		return err
 	}
+	if err := gate.applyBaseline(&scan); err != nil {
+		return err
+	}
 
 	var payload []byte
 	switch strings.ToLower(strings.TrimSpace(*outputFormat)) {
@@ -174,7 +665,7 @@ func runReport(args []string) error {
 	case "sarif":
 		payload, err = format.SARIF(scan)
 	default:
-		return fmt.Errorf("invalid format: %s", *outputFormat)
+		return usageError(fmt.Errorf("invalid format: %s", *outputFormat))
 	}
 	if err != nil {
 		return err
@@ -186,8 +677,10 @@ func runReport(args []string) error {
 		}
 	}
 
-	_, err = os.Stdout.Write(payload)
-	return err
+	if _, err := os.Stdout.Write(payload); err != nil {
+		return err
+	}
+	return gate.check(scan)
 }
 
 func runEnforce(ctx context.Context, args []string) error {
@@ -195,11 +688,17 @@ func runEnforce(ctx context.Context, args []string) error {
 	fs.SetOutput(os.Stderr)
 
 	from := fs.String("from", defaultScanPath, "Input scan JSON file")
-	project := fs.String("project", "", "Google Cloud project id (overrides scan file value)")
+	project := fs.String("project", "", "Only enforce findings of this Google Cloud project (defaults to the scan file value)")
 	apply := fs.Bool("apply", false, "Execute remediations (default dry-run)")
+	iamRecs := fs.Bool("iam-recommendations", false, "Include IAM Recommender role changes in the plan")
+	selFlags := addSelectionFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
-		return err
+		return usageError(err)
+	}
+	selection, err := selFlags.selection()
+	if err != nil {
+		return usageError(err)
 	}
 
 	scan, err := report.LoadScan(*from)
@@ -208,14 +707,24 @@ func runEnforce(ctx context.Context, args []string) error {
 	}
 
 	resolvedProject := strings.TrimSpace(*project)
-	if resolvedProject == "" {
+	if resolvedProject != "" {
+		if !scanCoversProject(scan, resolvedProject) {
+			return usageError(fmt.Errorf("--project %s does not match any project in %s", resolvedProject, *from))
+		}
+		scan.Findings = projectFindings(scan.Findings, resolvedProject)
+	} else {
 		resolvedProject = scan.Project
 	}
-	if resolvedProject == "" {
+	if resolvedProject == "" && len(scan.Projects) == 0 {
 		return errors.New("project is required for enforce; pass --project or include it in the scan file")
 	}
 
+	scan.Findings = selectFindings(scan, resolvedProject, selection)
+
 	actions := buildEnforceActions(scan, resolvedProject)
+	if *iamRecs {
+		actions = append(actions, buildRecommendationActions(scan, resolvedProject)...)
+	}
 	if len(actions) == 0 {
 		fmt.Fprintln(os.Stdout, "No auto-remediations available for current findings.")
 		return nil
@@ -225,6 +734,9 @@ func runEnforce(ctx context.Context, args []string) error {
 		fmt.Fprintln(os.Stdout, "Dry run: planned actions")
 		for _, act := range actions {
 			fmt.Fprintf(os.Stdout, "- [%s] %s\n", act.Kind, strings.Join(act.Cmd, " "))
+			for _, step := range act.Steps {
+				fmt.Fprintf(os.Stdout, "    then %s\n", strings.Join(step, " "))
+			}
 		}
 		fmt.Fprintln(os.Stdout, "\nRe-run with --apply to execute supported actions.")
 		return nil
@@ -233,7 +745,7 @@ func runEnforce(ctx context.Context, args []string) error {
 	runner := execx.OSRunner{}
 	success := 0
 	for _, act := range actions {
-		out, runErr := runner.Run(ctx, act.Cmd[0], act.Cmd[1:]...)
+		out, runErr := applyAction(ctx, runner, act)
 		if runErr != nil {
 			fmt.Fprintf(os.Stderr, "failed [%s]: %v\n", act.Kind, runErr)
 			continue
@@ -249,8 +761,42 @@ func runEnforce(ctx context.Context, args []string) error {
 }
 
 type enforceAction struct {
-	Kind string
-	Cmd  []string
+	Kind      string
+	Cmd       []string
+	Steps     [][]string
+	OnFailure []string
+}
+
+const etagPlaceholder = "<etag>"
+
+func applyAction(ctx context.Context, runner execx.Runner, act enforceAction) ([]byte, error) {
+	out, err := runner.Run(ctx, act.Cmd[0], act.Cmd[1:]...)
+	if err != nil || len(act.Steps) == 0 {
+		return out, err
+	}
+
+	etag := strings.TrimSpace(string(out))
+	for _, step := range act.Steps {
+		stepOut, stepErr := runner.Run(ctx, step[0], withEtag(step, etag)[1:]...)
+		if stepErr != nil {
+			if len(act.OnFailure) > 0 {
+				_, _ = runner.Run(ctx, act.OnFailure[0], withEtag(act.OnFailure, etag)[1:]...)
+			}
+			return nil, stepErr
+		}
+		if next := strings.TrimSpace(string(stepOut)); next != "" {
+			etag = next
+		}
+	}
+	return nil, nil
+}
+
+func withEtag(cmd []string, etag string) []string {
+	out := make([]string, len(cmd))
+	for i, arg := range cmd {
+		out[i] = strings.ReplaceAll(arg, etagPlaceholder, etag)
+	}
+	return out
 }
 
 func buildEnforceActions(scan model.ScanResult, project string) []enforceAction {
@@ -264,7 +810,8 @@ func buildEnforceActions(scan model.ScanResult, project string) []enforceAction
 
 		account := f.Metadata["service_account"]
 		keyName := f.Metadata["key_name"]
-		if account == "" || keyName == "" {
+		target := findingProject(f, project)
+		if account == "" || keyName == "" || target == "" {
 			continue
 		}
 
@@ -280,7 +827,7 @@ func buildEnforceActions(scan model.ScanResult, project string) []enforceAction
 		cmd := []string{
 			"gcloud", "iam", "service-accounts", "keys", "disable", keyID,
 			"--iam-account", account,
-			"--project", project,
+			"--project", target,
 		}
 		sig := strings.Join(cmd, " ")
 		if _, exists := seen[sig]; exists {
@@ -300,6 +847,204 @@ func buildEnforceActions(scan model.ScanResult, project string) []enforceAction
 	return actions
 }
 
+func buildRecommendationActions(scan model.ScanResult, project string) []enforceAction {
+	actions := make([]enforceAction, 0)
+	seen := map[string]struct{}{}
+
+	for _, f := range scan.Findings {
+		if f.ID != "gcp.iam_recommender.excess_permissions" {
+			continue
+		}
+
+		name := f.Metadata["recommendation"]
+		etag := f.Metadata["etag"]
+		member := f.Metadata["member"]
+		remove := splitList(f.Metadata["remove_roles"])
+		target := findingProject(f, project)
+		if name == "" || etag == "" || member == "" || len(remove) == 0 || target == "" {
+			continue
+		}
+		if _, exists := seen[name]; exists {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		recID := name[strings.LastIndex(name, "/")+1:]
+		recCmd := func(verb, etag string) []string {
+			return []string{
+				"gcloud", "recommender", "recommendations", verb, recID,
+				"--project", target,
+				"--location", "global",
+				"--recommender", "google.iam.policy.Recommender",
+				"--etag", etag,
+				"--format=value(etag)",
+			}
+		}
+
+		act := enforceAction{
+			Kind:      "apply_iam_recommendation",
+			Cmd:       recCmd("mark-claimed", etag),
+			OnFailure: recCmd("mark-failed", etagPlaceholder),
+		}
+		for _, role := range splitList(f.Metadata["add_roles"]) {
+			act.Steps = append(act.Steps, []string{
+				"gcloud", "projects", "add-iam-policy-binding", target,
+				"--member", member,
+				"--role", role,
+				"--condition=None",
+				"--format=none",
+			})
+		}
+		for _, role := range remove {
+			act.Steps = append(act.Steps, []string{
+				"gcloud", "projects", "remove-iam-policy-binding", target,
+				"--member", member,
+				"--role", role,
+				"--condition=None",
+				"--format=none",
+			})
+		}
+		act.Steps = append(act.Steps, recCmd("mark-succeeded", etagPlaceholder))
+		actions = append(actions, act)
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		return strings.Join(actions[i].Cmd, " ") < strings.Join(actions[j].Cmd, " ")
+	})
+
+	return actions
+}
+
+// fromFlag collects repeated --from flags; several files are merged.
+type fromFlag struct {
+	values []string
+}
+
+func addFromFlag(fs *flag.FlagSet) *fromFlag {
+	f := &fromFlag{}
+	fs.Var(f, "from", "Input scan JSON file; repeat to merge several (default "+defaultScanPath+")")
+	return f
+}
+
+func (f *fromFlag) String() string { return strings.Join(f.values, ",") }
+
+func (f *fromFlag) Set(v string) error {
+	f.values = append(f.values, v)
+	return nil
+}
+
+func (f *fromFlag) paths() []string {
+	if len(f.values) == 0 {
+		return []string{defaultScanPath}
+	}
+	return f.values
+}
+
+type selectionFlags struct {
+	include *string
+	exclude *string
+	labels  *string
+	states  *string
+}
+
+func addSelectionFlags(fs *flag.FlagSet) *selectionFlags {
+	return &selectionFlags{
+		include: fs.String("include-projects", "", "Comma-separated project id globs to include"),
+		exclude: fs.String("exclude-projects", "", "Comma-separated project id globs to exclude (for example sys-*)"),
+		labels:  fs.String("labels", "", "Comma-separated label selectors: key=value, key!=value, key, !key"),
+		states:  fs.String("lifecycle-states", "ACTIVE", "Comma-separated project lifecycle states to include, or any"),
+	}
+}
+
+func (f *selectionFlags) selection() (model.ProjectSelection, error) {
+	sel := model.ProjectSelection{
+		Include: splitList(*f.include),
+		Exclude: splitList(*f.exclude),
+		Labels:  splitList(*f.labels),
+		States:  splitList(*f.states),
+	}
+	return sel, sel.Validate()
+}
+
+func (f *selectionFlags) filtersProjects() bool {
+	return strings.TrimSpace(*f.include) != "" || strings.TrimSpace(*f.exclude) != "" || strings.TrimSpace(*f.labels) != ""
+}
+
+func selectFindings(scan model.ScanResult, project string, sel model.ProjectSelection) []model.Finding {
+	known := make(map[string]model.Project, len(scan.Projects))
+	for _, p := range scan.Projects {
+		known[p.ID] = p
+	}
+
+	out := make([]model.Finding, 0, len(scan.Findings))
+	for _, f := range scan.Findings {
+		id := findingProject(f, project)
+		if id == "" {
+			out = append(out, f)
+			continue
+		}
+		p, ok := known[id]
+		if !ok {
+			p = model.Project{ID: id}
+		}
+		if p.State == "" {
+			p.State = "ACTIVE"
+		}
+		if sel.Match(p) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// scanCoversProject reports whether the scan targeted the project or holds
+// findings for it.
+func scanCoversProject(scan model.ScanResult, project string) bool {
+	if scan.Project == project {
+		return true
+	}
+	for _, p := range scan.Projects {
+		if p.ID == project {
+			return true
+		}
+	}
+	for _, f := range scan.Findings {
+		if f.Project == project {
+			return true
+		}
+	}
+	return false
+}
+
+// projectFindings keeps the findings of project, and those that do not name
+// a project at all (scans written before findings carried one).
+func projectFindings(findings []model.Finding, project string) []model.Finding {
+	out := make([]model.Finding, 0, len(findings))
+	for _, f := range findings {
+		if f.Project == "" || f.Project == project {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func findingProject(f model.Finding, fallback string) string {
+	if f.Project != "" {
+		return f.Project
+	}
+	return fallback
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func renderRecommendationsTable(recs []report.Recommendation) string {
 	if len(recs) == 0 {
 		return "No recommendations.\n"
@@ -321,6 +1066,121 @@ func renderRecommendationsTable(recs []report.Recommendation) string {
 	return b.String()
 }
 
+func renderDiffTable(d baseline.Diff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "added: %d  resolved: %d  changed: %d  unchanged: %d  not evaluated: %d\n\n", len(d.Added), len(d.Resolved), len(d.Changed), d.Unchanged, len(d.NotEvaluated))
+	b.WriteString("CHANGE    SEVERITY         FINDING                                  RESOURCE\n")
+	b.WriteString("--------  ---------------  ---------------------------------------  --------\n")
+	row := func(change, severity string, f model.Finding) {
+		fmt.Fprintf(&b, "%-8s  %-15s  %-39s  %s\n", change, severity, truncate(f.ID, 39), f.Resource)
+	}
+	for _, f := range d.Added {
+		row("added", string(f.Severity), f)
+	}
+	for _, f := range d.Resolved {
+		row("resolved", string(f.Severity), f)
+	}
+	for _, c := range d.Changed {
+		severity := string(c.New.Severity)
+		if c.Old.Severity != c.New.Severity {
+			severity = string(c.Old.Severity) + "->" + severity
+		}
+		row("changed", severity, c.New)
+	}
+	for _, f := range d.NotEvaluated {
+		row("unknown", string(f.Severity), f)
+	}
+	return b.String()
+}
+
+func renderTrendTable(t history.Trend) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scope: %s  scans: %d  remediated: %d  open: %d", t.Scope, len(t.Points), len(t.Remediations), t.Open)
+	if len(t.Remediations) > 0 {
+		fmt.Fprintf(&b, "  mttr: %s", format.Age(t.MeanTimeToRemediate()))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("SCAN                  TOTAL  CRITICAL  HIGH  MEDIUM  LOW  INFO\n")
+	b.WriteString("--------------------  -----  --------  ----  ------  ---  ----\n")
+	for _, p := range t.Points {
+		fmt.Fprintf(&b, "%-20s  %5d  %8d  %4d  %6d  %3d  %4d\n",
+			p.GeneratedAt.UTC().Format("2006-01-02 15:04:05"), p.Total,
+			p.BySeverity[model.SeverityCritical], p.BySeverity[model.SeverityHigh],
+			p.BySeverity[model.SeverityMedium], p.BySeverity[model.SeverityLow], p.BySeverity[model.SeverityInfo])
+	}
+
+	if checks := t.Checks(); len(checks) > 0 {
+		b.WriteString("\nCHECK                                     TREND\n")
+		b.WriteString("----------------------------------------  -----\n")
+		for _, check := range checks {
+			values := make([]int, len(t.Points))
+			for i, p := range t.Points {
+				values[i] = p.ByCheck[check]
+			}
+			fmt.Fprintf(&b, "%-40s  %s %d\n", truncate(check, 40), format.Sparkline(values), values[len(values)-1])
+		}
+	}
+
+	if len(t.Remediations) > 0 {
+		b.WriteString("\nREMEDIATED                               OPEN FOR  RESOURCE\n")
+		b.WriteString("---------------------------------------  --------  --------\n")
+		for _, r := range t.Remediations {
+			fmt.Fprintf(&b, "%-39s  %-8s  %s\n", truncate(r.ID, 39), format.Age(r.Duration), r.Resource)
+		}
+	}
+	return b.String()
+}
+
+func renderChecksTable(infos []scanner.CheckInfo) string {
+	var b strings.Builder
+	b.WriteString("CHECK                     CATEGORY             SCOPES\n")
+	b.WriteString("------------------------  -------------------  ------\n")
+	for _, info := range infos {
+		scopes := make([]string, 0, len(info.Scopes))
+		for _, sc := range info.Scopes {
+			scopes = append(scopes, string(sc))
+		}
+		fmt.Fprintf(&b, "%-24s  %-19s  %s\n", info.ID, truncate(info.Category, 19), strings.Join(scopes, ","))
+		fmt.Fprintf(&b, "  %s: %s\n", info.Title, info.Description)
+		for _, id := range info.FindingIDs {
+			fmt.Fprintf(&b, "  - %s\n", id)
+		}
+	}
+	return b.String()
+}
+
+func renderPreflight(pre scanner.Preflight) string {
+	var b strings.Builder
+	b.WriteString("gcpsec doctor\n")
+	fmt.Fprintf(&b, "- backend: %s\n", pre.Backend)
+	if pre.GCloudPath != "" {
+		fmt.Fprintf(&b, "- gcloud: %s (%s)\n", pre.GCloudPath, pre.GCloudVersion)
+	}
+	if pre.Account != "" {
+		fmt.Fprintf(&b, "- account: %s\n", pre.Account)
+	}
+	if pre.Target != "" {
+		state := "not verified"
+		if pre.TargetExists {
+			state = "found"
+		}
+		fmt.Fprintf(&b, "- target: %s (%s)\n", pre.Target, state)
+	}
+	if len(pre.Problems) > 0 {
+		b.WriteString("- problems:\n")
+		for _, p := range pre.Problems {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+	}
+	b.WriteString("\nCHECK                     STATUS   REASON\n")
+	b.WriteString("------------------------  -------  ------\n")
+	for _, c := range pre.Checks {
+		fmt.Fprintf(&b, "%-24s  %-7s  %s\n", c.ID, c.Status, c.Reason)
+	}
+	return b.String()
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -342,6 +1202,21 @@ func printRootUsage(w *os.File) {
 	fmt.Fprintln(w, "  recommend  Convert findings into prioritized actions")
 	fmt.Fprintln(w, "  enforce    Apply safe remediations (dry-run by default)")
 	fmt.Fprintln(w, "  report     Render scan output as markdown/json/sarif")
+	fmt.Fprintln(w, "  checks     List available checks and the findings they produce")
+	fmt.Fprintln(w, "  doctor     Verify gcloud, credentials and per-check permissions")
+	fmt.Fprintln(w, "  baseline   Record current findings so later scans only flag new ones")
+	fmt.Fprintln(w, "  diff       Compare two scans: added, resolved and changed findings")
+	fmt.Fprintln(w, "  trend      Findings over time and time to remediate from scan history")
+	fmt.Fprintln(w, "  validate   Check scan files against the published JSON Schema")
+	fmt.Fprintln(w, "  merge      Combine several scans into one, deduplicating findings")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Exit codes:")
+	fmt.Fprintln(w, "  0  success")
+	fmt.Fprintln(w, "  1  tool error")
+	fmt.Fprintln(w, "  2  invalid usage")
+	fmt.Fprintln(w, "  3  findings at or above --fail-on / --fail-on-new (diff: added findings)")
+	fmt.Fprintln(w, "  4  checks errored (--fail-on-errors)")
+	fmt.Fprintln(w, "  5  no check ran while a --fail-on* gate was set")
 }
 
 func printSummary(w *os.File, result model.ScanResult, outPath string) {
@@ -351,6 +1226,9 @@ func printSummary(w *os.File, result model.ScanResult, outPath string) {
 	}
 
 	fmt.Fprintln(w, "gcpsec scan summary")
+	if len(result.Projects) > 0 {
+		fmt.Fprintf(w, "- projects: %d\n", len(result.Projects))
+	}
 	fmt.Fprintf(w, "- findings: %d\n", len(result.Findings))
 	fmt.Fprintf(w, "- critical: %d\n", counts[model.SeverityCritical])
 	fmt.Fprintf(w, "- high: %d\n", counts[model.SeverityHigh])
@@ -360,6 +1238,24 @@ func printSummary(w *os.File, result model.ScanResult, outPath string) {
 	if outPath != "" {
 		fmt.Fprintf(w, "- saved: %s\n", outPath)
 	}
+	if result.Fixed != nil || hasBaselineState(result.Findings) {
+		states := map[string]int{}
+		for _, f := range result.Findings {
+			states[f.BaselineState]++
+		}
+		fmt.Fprintf(w, "- baseline: %d new, %d existing, %d fixed\n", states[model.BaselineNew], states[model.BaselineExisting], len(result.Fixed))
+	}
+	if len(result.Executions) > 0 {
+		statuses := map[model.CheckStatus]int{}
+		for _, e := range result.Executions {
+			statuses[e.Status]++
+		}
+		fmt.Fprintf(w, "- checks: %d passed, %d failed, %d errored, %d skipped\n",
+			statuses[model.CheckPassed], statuses[model.CheckFailed], statuses[model.CheckErrored], statuses[model.CheckSkipped])
+	}
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(w, "- resource errors: %d\n", len(result.Errors))
+	}
 	if len(result.Notes) > 0 {
 		fmt.Fprintln(w, "- notes:")
 		for _, note := range result.Notes {
@@ -367,3 +1263,12 @@ func printSummary(w *os.File, result model.ScanResult, outPath string) {
 		}
 	}
 }
+
+func hasBaselineState(findings []model.Finding) bool {
+	for _, f := range findings {
+		if f.BaselineState != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -31,3 +31,71 @@ func TestBuildEnforceActionsForStaleKeys(t *testing.T) {
 		t.Fatalf("expected key id %s, got %s", wantLast, got[5])
 	}
 }
+
+func TestBuildRecommendationActionsGrantsBeforeRevoking(t *testing.T) {
+	scan := model.ScanResult{
+		Project: "demo-project",
+		Findings: []model.Finding{
+			{
+				ID: "gcp.iam_recommender.excess_permissions",
+				Metadata: map[string]string{
+					"recommendation": "projects/123/locations/global/recommenders/google.iam.policy.Recommender/recommendations/rec-1",
+					"etag":           "\"abc\"",
+					"member":         "user:dev@example.com",
+					"remove_roles":   "roles/editor",
+					"add_roles":      "roles/viewer",
+				},
+			},
+		},
+	}
+
+	actions := buildRecommendationActions(scan, scan.Project)
+	if len(actions) != 1 {
+		t.Fatalf("expected one action, got %d", len(actions))
+	}
+
+	act := actions[0]
+	if act.Cmd[3] != "mark-claimed" || act.Cmd[4] != "rec-1" {
+		t.Fatalf("expected mark-claimed rec-1, got %v", act.Cmd)
+	}
+	if len(act.Steps) != 3 {
+		t.Fatalf("expected grant, revoke and mark-succeeded steps, got %d", len(act.Steps))
+	}
+	if act.Steps[0][2] != "add-iam-policy-binding" || act.Steps[1][2] != "remove-iam-policy-binding" {
+		t.Fatalf("expected grant before revoke, got %v then %v", act.Steps[0], act.Steps[1])
+	}
+	if act.Steps[2][3] != "mark-succeeded" {
+		t.Fatalf("expected final mark-succeeded step, got %v", act.Steps[2])
+	}
+}
+
+func TestSelectFindingsHonoursLabelsFromScan(t *testing.T) {
+	scan := model.ScanResult{
+		Projects: []model.Project{
+			{ID: "app-prod", State: "ACTIVE", Labels: map[string]string{"env": "prod"}},
+			{ID: "app-dev", State: "ACTIVE", Labels: map[string]string{"env": "dev"}},
+		},
+		Findings: []model.Finding{
+			{ID: "gcp.sa_key.stale_review", Project: "app-prod"},
+			{ID: "gcp.sa_key.stale_review", Project: "app-dev"},
+		},
+	}
+
+	got := selectFindings(scan, "", model.ProjectSelection{Labels: []string{"env=prod"}, States: []string{"ACTIVE"}})
+	if len(got) != 1 || got[0].Project != "app-prod" {
+		t.Fatalf("expected only app-prod finding, got %+v", got)
+	}
+}
+
+func TestProjectFindingsKeepsOnlyTheRequestedProject(t *testing.T) {
+	findings := []model.Finding{
+		{ID: "gcp.sa_key.stale_review", Project: "app-prod"},
+		{ID: "gcp.sa_key.stale_review", Project: "app-dev"},
+		{ID: "gcp.sa_key.no_expiry"},
+	}
+
+	got := projectFindings(findings, "app-dev")
+	if len(got) != 2 || got[0].Project != "app-dev" || got[1].Project != "" {
+		t.Fatalf("expected the app-dev finding and the one without a project, got %+v", got)
+	}
+}
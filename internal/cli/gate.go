@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/baseline"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+	"github.com/Andrei-Barwood/gcpsec/internal/scanner"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK            = 0
+	ExitError         = 1
+	ExitUsage         = 2
+	ExitFindings      = 3
+	ExitCheckErrors   = 4
+	ExitChecksSkipped = 5
+)
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func usageError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return &exitError{code: ExitOK, err: err}
+	}
+	return &exitError{code: ExitUsage, err: err}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	return ExitError
+}
+
+type gateFlags struct {
+	failOn       *string
+	failOnNew    *string
+	failOnErrors *bool
+	baseline     *string
+}
+
+func addGateFlags(fs *flag.FlagSet) *gateFlags {
+	return &gateFlags{
+		failOn:       fs.String("fail-on", "", "Exit 3 when a finding at or above this severity exists: info|low|medium|high|critical"),
+		failOnNew:    fs.String("fail-on-new", "", "Exit 3 when a finding at or above this severity is not in --baseline"),
+		failOnErrors: fs.Bool("fail-on-errors", false, "Exit 4 when any check errored"),
+		baseline:     fs.String("baseline", "", "Baseline file (or previous scan JSON) used to mark findings new, existing or fixed"),
+	}
+}
+
+func (g *gateFlags) validate() error {
+	for _, v := range []string{*g.failOn, *g.failOnNew} {
+		if v == "" {
+			continue
+		}
+		if _, err := model.ParseSeverity(v); err != nil {
+			return err
+		}
+	}
+	if *g.failOnNew != "" && *g.baseline == "" {
+		return errors.New("--fail-on-new requires --baseline")
+	}
+	return nil
+}
+
+// applyBaseline marks result against --baseline when one was given.
+func (g *gateFlags) applyBaseline(result *model.ScanResult) error {
+	if *g.baseline == "" {
+		return nil
+	}
+	b, err := baseline.Load(*g.baseline)
+	if err != nil {
+		return err
+	}
+	baseline.Apply(result, b)
+	return nil
+}
+
+func (g *gateFlags) enabled() bool {
+	return *g.failOn != "" || *g.failOnNew != "" || *g.failOnErrors
+}
+
+// check applies the gates to a scan and returns an exitError describing the
+// first one that trips: findings first, then errored checks, then a scan in
+// which no check actually ran. When the scan targeted a project, folder or
+// organization, at least one remote check must have passed or failed.
+func (g *gateFlags) check(result model.ScanResult) error {
+	if !g.enabled() {
+		return nil
+	}
+
+	if *g.failOn != "" {
+		threshold, _ := model.ParseSeverity(*g.failOn)
+		if n := countAtLeast(result.Findings, threshold); n > 0 {
+			return &exitError{code: ExitFindings, err: fmt.Errorf("%d finding(s) at or above %s", n, threshold)}
+		}
+	}
+	if *g.failOnNew != "" {
+		var fresh []model.Finding
+		for _, f := range result.Findings {
+			if f.BaselineState == model.BaselineNew {
+				fresh = append(fresh, f)
+			}
+		}
+		threshold, _ := model.ParseSeverity(*g.failOnNew)
+		if n := countAtLeast(fresh, threshold); n > 0 {
+			return &exitError{code: ExitFindings, err: fmt.Errorf("%d new finding(s) at or above %s", n, threshold)}
+		}
+	}
+
+	statuses := map[model.CheckStatus]int{}
+	for _, e := range result.Executions {
+		statuses[e.Status]++
+	}
+	if *g.failOnErrors && statuses[model.CheckErrored] > 0 {
+		return &exitError{code: ExitCheckErrors, err: fmt.Errorf("%d check(s) errored", statuses[model.CheckErrored])}
+	}
+	// Scans written before execution records existed have none; that says
+	// nothing about whether checks ran.
+	if result.Legacy {
+		return nil
+	}
+	if result.Project != "" || result.Organization != "" || result.Folder != "" {
+		if !remoteCheckRan(result.Executions) {
+			return &exitError{code: ExitChecksSkipped, err: errors.New("no remote check ran against the requested target")}
+		}
+		return nil
+	}
+	if statuses[model.CheckSkipped] == len(result.Executions) {
+		return &exitError{code: ExitChecksSkipped, err: errors.New("no check ran; every check was skipped")}
+	}
+	return nil
+}
+
+func remoteCheckRan(executions []model.CheckExecution) bool {
+	remote := map[string]bool{}
+	for _, c := range scanner.Checks() {
+		info := c.Info()
+		remote[info.ID] = info.Remote()
+	}
+	for _, e := range executions {
+		if remote[e.Check] && (e.Status == model.CheckPassed || e.Status == model.CheckFailed) {
+			return true
+		}
+	}
+	return false
+}
+
+func countAtLeast(findings []model.Finding, threshold model.Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity.Rank() >= threshold.Rank() {
+			n++
+		}
+	}
+	return n
+}
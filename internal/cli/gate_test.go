@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/format"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestGateExitCodes(t *testing.T) {
+	previous := model.ScanResult{Findings: []model.Finding{
+		{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "old-key", Severity: model.SeverityHigh},
+	}}
+	payload, err := format.JSON(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(baseline, payload, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ran := []model.CheckExecution{{Check: "api-keys", Status: model.CheckFailed}}
+	cases := []struct {
+		name   string
+		args   []string
+		result model.ScanResult
+		want   int
+	}{
+		{
+			name:   "no gates",
+			result: model.ScanResult{Findings: []model.Finding{{Severity: model.SeverityCritical}}},
+			want:   ExitOK,
+		},
+		{
+			name:   "below threshold",
+			args:   []string{"--fail-on", "high"},
+			result: model.ScanResult{Findings: []model.Finding{{Severity: model.SeverityMedium}}, Executions: ran},
+			want:   ExitOK,
+		},
+		{
+			name:   "above threshold",
+			args:   []string{"--fail-on", "HIGH"},
+			result: model.ScanResult{Findings: []model.Finding{{Severity: model.SeverityCritical}}, Executions: ran},
+			want:   ExitFindings,
+		},
+		{
+			name: "only known findings",
+			args: []string{"--fail-on-new", "high", "--baseline", baseline},
+			result: model.ScanResult{Findings: []model.Finding{
+				{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "old-key", Severity: model.SeverityHigh},
+			}, Executions: ran},
+			want: ExitOK,
+		},
+		{
+			name: "new finding",
+			args: []string{"--fail-on-new", "high", "--baseline", baseline},
+			result: model.ScanResult{Findings: []model.Finding{
+				{ID: "gcp.api_key.unrestricted", Project: "demo", Resource: "new-key", Severity: model.SeverityHigh},
+			}, Executions: ran},
+			want: ExitFindings,
+		},
+		{
+			name: "errored check",
+			args: []string{"--fail-on-errors"},
+			result: model.ScanResult{Executions: []model.CheckExecution{
+				{Check: "api-keys", Status: model.CheckPassed},
+				{Check: "org-policies", Status: model.CheckErrored},
+			}},
+			want: ExitCheckErrors,
+		},
+		{
+			name: "everything skipped",
+			args: []string{"--fail-on", "critical"},
+			result: model.ScanResult{Executions: []model.CheckExecution{
+				{Check: "billing-budgets", Status: model.CheckSkipped},
+			}},
+			want: ExitChecksSkipped,
+		},
+		{
+			name:   "legacy scan without execution records",
+			args:   []string{"--fail-on", "critical"},
+			result: model.ScanResult{Findings: []model.Finding{{Severity: model.SeverityLow}}, Legacy: true},
+			want:   ExitOK,
+		},
+		{
+			name:   "no execution records",
+			args:   []string{"--fail-on", "critical"},
+			result: model.ScanResult{Findings: []model.Finding{{Severity: model.SeverityLow}}},
+			want:   ExitChecksSkipped,
+		},
+		{
+			name: "only local checks ran for a project",
+			args: []string{"--fail-on", "high"},
+			result: model.ScanResult{Project: "demo", Executions: []model.CheckExecution{
+				{Check: "local-secrets", Status: model.CheckPassed},
+				{Check: "api-keys", Status: model.CheckSkipped, Reason: "gcloud_not_found"},
+			}},
+			want: ExitChecksSkipped,
+		},
+		{
+			name: "remote check ran for a project",
+			args: []string{"--fail-on", "high"},
+			result: model.ScanResult{Project: "demo", Executions: []model.CheckExecution{
+				{Check: "local-secrets", Status: model.CheckPassed},
+				{Check: "api-keys", Status: model.CheckPassed},
+				{Check: "org-policies", Status: model.CheckSkipped},
+			}},
+			want: ExitOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			gate := addGateFlags(fs)
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatal(err)
+			}
+			if err := gate.validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gate.applyBaseline(&tc.result); err != nil {
+				t.Fatal(err)
+			}
+			if got := exitCode(gate.check(tc.result)); got != tc.want {
+				t.Fatalf("exit code = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunReturnsUsageExitCode(t *testing.T) {
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	payload, err := format.JSON(model.ScanResult{Project: "demo", Findings: []model.Finding{{ID: "gcp.sa_key.no_expiry", Project: "demo"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(scan, payload, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"report", "--no-such-flag"},
+		{"report", "--fail-on", "severe"},
+		{"scan", "--fail-on-new", "high"},
+		{"scan", "--project", "a", "--folder", "b"},
+		{"scan", "--backend", "foo"},
+		{"scan", "--asset-export", "assets.jsonl", "--organization", "1"},
+		{"scan", "--include-projects", "app-*"},
+		{"scan", "--record", "a", "--replay", "b"},
+		{"scan", "--checks", "no-such-check"},
+		{"doctor", "--backend", "foo"},
+		{"enforce", "--include-projects", "["},
+		{"enforce", "--from", scan, "--project", "other"},
+	} {
+		if got := Run(context.Background(), args); got != ExitUsage {
+			t.Errorf("Run(%v) = %d, want %d", args, got, ExitUsage)
+		}
+	}
+}
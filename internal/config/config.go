@@ -0,0 +1,34 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type Config struct {
+	EssentialContacts EssentialContacts     `json:"essential_contacts"`
+	OrgPolicies       []model.OrgPolicyRule `json:"org_policies,omitempty"`
+}
+
+type EssentialContacts struct {
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+}
+
+func Load(path string) (Config, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
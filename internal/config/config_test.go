@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"essential_contact": {}}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected error for misspelled section")
+	}
+}
+
+func TestLoadAllowedDomains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	body := `{"essential_contacts": {"allowed_domains": ["example.com"]}}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if len(cfg.EssentialContacts.AllowedDomains) != 1 || cfg.EssentialContacts.AllowedDomains[0] != "example.com" {
+		t.Fatalf("unexpected allowed domains: %v", cfg.EssentialContacts.AllowedDomains)
+	}
+}
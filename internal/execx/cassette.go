@@ -0,0 +1,141 @@
+package execx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const CassetteFile = "cassette.json"
+
+type Interaction struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exit_code"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func (i Interaction) key() string {
+	return commandLine(i.Command, i.Args)
+}
+
+func commandLine(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+type RecordingRunner struct {
+	next Runner
+	path string
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+func NewRecordingRunner(next Runner, dir string) (*RecordingRunner, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &RecordingRunner{next: next, path: filepath.Join(dir, CassetteFile)}, nil
+}
+
+func (r *RecordingRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := r.next.Run(ctx, name, args...)
+
+	rec := Interaction{Command: name, Args: append([]string(nil), args...), Stdout: string(out)}
+	var cmdErr *CommandError
+	switch {
+	case errors.As(err, &cmdErr):
+		rec.ExitCode = cmdErr.ExitCode
+		rec.Stderr = cmdErr.Stderr
+		rec.Error = cmdErr.Err.Error()
+	case err != nil:
+		rec.ExitCode = -1
+		rec.Error = err.Error()
+	}
+
+	if saveErr := r.append(rec); saveErr != nil && err == nil {
+		return out, fmt.Errorf("record %s: %w", rec.key(), saveErr)
+	}
+	return out, err
+}
+
+func (r *RecordingRunner) LookPath(file string) (string, error) {
+	return r.next.LookPath(file)
+}
+
+func (r *RecordingRunner) append(rec Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, rec)
+	sorted := append([]Interaction(nil), r.interactions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].key() < sorted[j].key() })
+
+	buf, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(buf, '\n'), 0o644)
+}
+
+type ReplayRunner struct {
+	mu     sync.Mutex
+	byKey  map[string][]Interaction
+	served map[string]int
+}
+
+func NewReplayRunner(dir string) (*ReplayRunner, error) {
+	buf, err := os.ReadFile(filepath.Join(dir, CassetteFile))
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(buf, &interactions); err != nil {
+		return nil, fmt.Errorf("parse cassette in %s: %w", dir, err)
+	}
+
+	r := &ReplayRunner{byKey: map[string][]Interaction{}, served: map[string]int{}}
+	for _, i := range interactions {
+		r.byKey[i.key()] = append(r.byKey[i.key()], i)
+	}
+	return r, nil
+}
+
+func (r *ReplayRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	key := commandLine(name, args)
+
+	r.mu.Lock()
+	recorded := r.byKey[key]
+	n := r.served[key]
+	r.served[key]++
+	r.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s", key)
+	}
+	if n >= len(recorded) {
+		n = len(recorded) - 1
+	}
+	rec := recorded[n]
+
+	if rec.Error == "" && rec.ExitCode == 0 {
+		return []byte(rec.Stdout), nil
+	}
+	msg := rec.Error
+	if msg == "" {
+		msg = fmt.Sprintf("exit status %d", rec.ExitCode)
+	}
+	return nil, &CommandError{Name: name, Args: args, ExitCode: rec.ExitCode, Stderr: rec.Stderr, Err: errors.New(msg)}
+}
+
+func (r *ReplayRunner) LookPath(file string) (string, error) {
+	return "/replay/" + file, nil
+}
@@ -0,0 +1,53 @@
+package execx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeRunner struct{}
+
+func (fakeRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	if args[0] == "fail" {
+		return nil, &CommandError{Name: name, Args: args, ExitCode: 2, Stderr: "PERMISSION_DENIED", Err: errors.New("exit status 2")}
+	}
+	return []byte(`[{"name": "ok"}]`), nil
+}
+
+func (fakeRunner) LookPath(file string) (string, error) { return "/bin/" + file, nil }
+
+func TestRecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecordingRunner(fakeRunner{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := rec.Run(ctx, "gcloud", "list", "--format=json"); err != nil {
+		t.Fatalf("record run failed: %v", err)
+	}
+	if _, err := rec.Run(ctx, "gcloud", "fail"); err == nil {
+		t.Fatal("expected recorded failure to be returned")
+	}
+
+	replay, err := NewReplayRunner(dir)
+	if err != nil {
+		t.Fatalf("load cassette: %v", err)
+	}
+	out, err := replay.Run(ctx, "gcloud", "list", "--format=json")
+	if err != nil || string(out) != `[{"name": "ok"}]` {
+		t.Fatalf("unexpected replay output %q (%v)", out, err)
+	}
+
+	_, err = replay.Run(ctx, "gcloud", "fail")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.ExitCode != 2 || !strings.Contains(err.Error(), "PERMISSION_DENIED") {
+		t.Fatalf("expected replayed command error, got %v", err)
+	}
+
+	if _, err := replay.Run(ctx, "gcloud", "unknown"); err == nil {
+		t.Fatal("expected error for unrecorded command")
+	}
+}
@@ -3,6 +3,7 @@ package execx
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 )
@@ -12,6 +13,25 @@ type Runner interface {
 	LookPath(file string) (string, error)
 }
 
+type CommandError struct {
+	Name     string
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s %v failed: %v: %s", e.Name, e.Args, e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("%s %v failed: %v", e.Name, e.Args, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
 type OSRunner struct{}
 
 func (OSRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
@@ -21,10 +41,12 @@ func (OSRunner) Run(ctx context.Context, name string, args ...string) ([]byte, e
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		if stderr.Len() > 0 {
-			return nil, fmt.Errorf("%s %v failed: %w: %s", name, args, err, stderr.String())
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
 		}
-		return nil, fmt.Errorf("%s %v failed: %w", name, args, err)
+		return nil, &CommandError{Name: name, Args: args, ExitCode: exitCode, Stderr: stderr.String(), Err: err}
 	}
 	return stdout.Bytes(), nil
 }
@@ -0,0 +1,74 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/baseline"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func DiffMarkdown(d baseline.Diff) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("# gcpsec diff\n\n")
+	fmt.Fprintf(&b, "- Old scan: `%s`\n", d.OldGeneratedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(&b, "- New scan: `%s`\n", d.NewGeneratedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(&b, "- Added: `%d`, resolved: `%d`, changed: `%d`, unchanged: `%d`, not evaluated: `%d`\n\n", len(d.Added), len(d.Resolved), len(d.Changed), d.Unchanged, len(d.NotEvaluated))
+
+	if len(d.Added)+len(d.Resolved)+len(d.Changed)+len(d.NotEvaluated) == 0 {
+		b.WriteString("No changes.\n")
+		return []byte(b.String()), nil
+	}
+
+	if len(d.Added) > 0 {
+		b.WriteString("## Added\n\n")
+		for _, f := range d.Added {
+			fmt.Fprintf(&b, "- **[%s]** %s%s\n", strings.ToUpper(string(f.Severity)), f.Summary, diffLocation(f))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Resolved) > 0 {
+		b.WriteString("## Resolved\n\n")
+		for _, f := range d.Resolved {
+			fmt.Fprintf(&b, "- ~~[%s] %s~~%s\n", strings.ToUpper(string(f.Severity)), f.Summary, diffLocation(f))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.Changed) > 0 {
+		b.WriteString("## Changed\n\n")
+		for _, c := range d.Changed {
+			fmt.Fprintf(&b, "- [%s] %s%s: %s\n", diffSeverity(c), c.New.Summary, diffLocation(c.New), strings.Join(c.Fields, ", "))
+		}
+		b.WriteString("\n")
+	}
+	if len(d.NotEvaluated) > 0 {
+		b.WriteString("## Not evaluated\n\n")
+		b.WriteString("Missing from the new scan, but their check did not complete there.\n\n")
+		for _, f := range d.NotEvaluated {
+			fmt.Fprintf(&b, "- [%s] %s%s\n", strings.ToUpper(string(f.Severity)), f.Summary, diffLocation(f))
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String()), nil
+}
+
+func diffLocation(f model.Finding) string {
+	var parts []string
+	if f.Resource != "" {
+		parts = append(parts, fmt.Sprintf("`%s`", f.Resource))
+	}
+	if f.Project != "" {
+		parts = append(parts, fmt.Sprintf("project `%s`", f.Project))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " — " + strings.Join(parts, ", ")
+}
+
+func diffSeverity(c baseline.Change) string {
+	if c.Old.Severity == c.New.Severity {
+		return strings.ToUpper(string(c.New.Severity))
+	}
+	return strings.ToUpper(string(c.Old.Severity)) + " → " + strings.ToUpper(string(c.New.Severity))
+}
@@ -11,6 +11,9 @@ import (
 )
 
 func JSON(result model.ScanResult) ([]byte, error) {
+	if result.Findings == nil {
+		result.Findings = []model.Finding{}
+	}
 	return json.MarshalIndent(result, "", "  ")
 }
 
@@ -21,9 +24,24 @@ func Markdown(result model.ScanResult) ([]byte, error) {
 	if result.Project != "" {
 		fmt.Fprintf(&b, "- Project: `%s`\n", result.Project)
 	}
+	if result.Organization != "" {
+		fmt.Fprintf(&b, "- Organization: `%s`\n", result.Organization)
+	}
+	if result.Folder != "" {
+		fmt.Fprintf(&b, "- Folder: `%s`\n", result.Folder)
+	}
+	if len(result.Projects) > 0 {
+		fmt.Fprintf(&b, "- Projects scanned: `%d`\n", len(result.Projects))
+	}
+	if sel := result.Selection; sel != nil && !sel.IsZero() {
+		fmt.Fprintf(&b, "- Project selection: `%s`\n", describeSelection(*sel))
+	}
 	if result.Repo != "" {
 		fmt.Fprintf(&b, "- Repo: `%s`\n", result.Repo)
 	}
+	if len(result.Sources) > 1 {
+		fmt.Fprintf(&b, "- Merged from: %s\n", describeSources(result.Sources))
+	}
 	fmt.Fprintf(&b, "- Findings: `%d`\n\n", len(result.Findings))
 
 	if len(result.Findings) == 0 {
@@ -31,20 +49,69 @@ func Markdown(result model.ScanResult) ([]byte, error) {
 	} else {
 		sorted := append([]model.Finding(nil), result.Findings...)
 		sort.SliceStable(sorted, func(i, j int) bool {
-			return severityWeight(sorted[i].Severity) > severityWeight(sorted[j].Severity)
+			return sorted[i].Severity.Rank() > sorted[j].Severity.Rank()
 		})
 
 		for _, f := range sorted {
-			fmt.Fprintf(&b, "## [%s] %s\n\n", strings.ToUpper(string(f.Severity)), f.Summary)
+			if f.BaselineState != "" {
+				fmt.Fprintf(&b, "## [%s] [%s] %s\n\n", strings.ToUpper(string(f.Severity)), strings.ToUpper(f.BaselineState), f.Summary)
+			} else {
+				fmt.Fprintf(&b, "## [%s] %s\n\n", strings.ToUpper(string(f.Severity)), f.Summary)
+			}
 			fmt.Fprintf(&b, "- Check: `%s`\n", f.Check)
+			if f.Project != "" && f.Project != result.Project {
+				fmt.Fprintf(&b, "- Project: `%s`\n", f.Project)
+			}
 			if f.Resource != "" {
 				fmt.Fprintf(&b, "- Resource: `%s`\n", f.Resource)
 			}
+			if len(result.Sources) > 1 && len(f.Sources) > 0 {
+				fmt.Fprintf(&b, "- Sources: %s\n", describeSources(f.Sources))
+			}
 			fmt.Fprintf(&b, "- Description: %s\n", f.Description)
 			fmt.Fprintf(&b, "- Recommendation: %s\n\n", f.Recommendation)
 		}
 	}
 
+	if len(result.Fixed) > 0 {
+		b.WriteString("## Fixed since baseline\n\n")
+		for _, f := range result.Fixed {
+			label := f.Summary
+			if label == "" {
+				label = f.ID
+			}
+			if f.Resource != "" {
+				fmt.Fprintf(&b, "- [%s] %s (`%s`)\n", strings.ToUpper(string(f.Severity)), label, f.Resource)
+			} else {
+				fmt.Fprintf(&b, "- [%s] %s\n", strings.ToUpper(string(f.Severity)), label)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Executions) > 0 {
+		b.WriteString("## Check executions\n\n")
+		b.WriteString("| Check | Target | Status | Reason | Error | Resources | Findings | Duration |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, e := range result.Executions {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %d | %d | %dms |\n",
+				e.Check, e.Target, e.Status, e.Reason, e.ErrorCategory, e.ResourcesEvaluated, e.Findings, e.DurationMillis)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(result.Errors) > 0 {
+		b.WriteString("## Errors\n\n")
+		for _, e := range result.Errors {
+			if e.Project != "" && e.Project != result.Project {
+				fmt.Fprintf(&b, "- `%s` (%s, project `%s`): %s\n", e.Resource, e.Check, e.Project, e.Message)
+				continue
+			}
+			fmt.Fprintf(&b, "- `%s` (%s): %s\n", e.Resource, e.Check, e.Message)
+		}
+		b.WriteString("\n")
+	}
+
 	if len(result.Notes) > 0 {
 		b.WriteString("## Notes\n\n")
 		for _, note := range result.Notes {
@@ -73,10 +140,12 @@ func SARIF(result model.ScanResult) ([]byte, error) {
 		} `json:"physicalLocation"`
 	}
 	type resultItem struct {
-		RuleID    string     `json:"ruleId"`
-		Level     string     `json:"level"`
-		Message   any        `json:"message"`
-		Locations []location `json:"locations,omitempty"`
+		RuleID              string            `json:"ruleId"`
+		Level               string            `json:"level"`
+		Message             any               `json:"message"`
+		Locations           []location        `json:"locations,omitempty"`
+		PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+		BaselineState       string            `json:"baselineState,omitempty"`
 	}
 
 	rulesByID := make(map[string]rule)
@@ -90,9 +159,13 @@ func SARIF(result model.ScanResult) ([]byte, error) {
 		}
 
 		item := resultItem{
-			RuleID:  f.ID,
-			Level:   sarifLevel(f.Severity),
-			Message: map[string]string{"text": fmt.Sprintf("%s Recommendation: %s", f.Description, f.Recommendation)},
+			RuleID:        f.ID,
+			Level:         sarifLevel(f.Severity),
+			Message:       map[string]string{"text": fmt.Sprintf("%s Recommendation: %s", f.Description, f.Recommendation)},
+			BaselineState: sarifBaselineState(f.BaselineState),
+		}
+		if f.Fingerprint != "" {
+			item.PartialFingerprints = map[string]string{model.FingerprintVersion: f.Fingerprint}
 		}
 		if f.Resource != "" && !strings.Contains(f.Resource, "@") {
 			item.Locations = []location{{}}
@@ -107,39 +180,104 @@ func SARIF(result model.ScanResult) ([]byte, error) {
 	}
 	sort.SliceStable(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
 
+	run := map[string]any{
+		"tool": map[string]any{
+			"driver": map[string]any{
+				"name":            "gcpsec",
+				"informationUri":  "https://github.com/Andrei-Barwood/gcpsec",
+				"semanticVersion": "0.1.0",
+				"rules":           rules,
+			},
+		},
+		"results": results,
+	}
+	if len(result.Executions) > 0 {
+		run["invocations"] = []any{sarifInvocation(result.Executions)}
+	}
+
 	payload := map[string]any{
 		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
 		"version": "2.1.0",
-		"runs": []any{
-			map[string]any{
-				"tool": map[string]any{
-					"driver": map[string]any{
-						"name":            "gcpsec",
-						"informationUri":  "https://github.com/Andrei-Barwood/gcpsec",
-						"semanticVersion": "0.1.0",
-						"rules":           rules,
-					},
-				},
-				"results": results,
-			},
-		},
+		"runs":    []any{run},
 	}
 
 	return json.MarshalIndent(payload, "", "  ")
 }
 
-func severityWeight(s model.Severity) int {
-	switch s {
-	case model.SeverityCritical:
-		return 5
-	case model.SeverityHigh:
-		return 4
-	case model.SeverityMedium:
-		return 3
-	case model.SeverityLow:
-		return 2
+// sarifInvocation reports each check execution as a tool execution
+// notification; the invocation only counts as successful when no check
+// errored.
+func sarifInvocation(executions []model.CheckExecution) map[string]any {
+	successful := true
+	notifications := make([]map[string]any, 0, len(executions))
+	for _, e := range executions {
+		level := "note"
+		switch e.Status {
+		case model.CheckErrored:
+			level = "error"
+			successful = false
+		case model.CheckSkipped:
+			level = "warning"
+		}
+		text := fmt.Sprintf("%s %s on %s (%s)", e.Check, e.Status, e.Target, e.Reason)
+		if e.Message != "" {
+			text += ": " + e.Message
+		}
+		properties := map[string]any{
+			"status":             e.Status,
+			"reason":             e.Reason,
+			"durationMs":         e.DurationMillis,
+			"resourcesEvaluated": e.ResourcesEvaluated,
+			"findings":           e.Findings,
+		}
+		if e.ErrorCategory != "" {
+			properties["errorCategory"] = e.ErrorCategory
+		}
+		if e.Project != "" {
+			properties["project"] = e.Project
+		}
+		notifications = append(notifications, map[string]any{
+			"level":      level,
+			"message":    map[string]string{"text": text},
+			"descriptor": map[string]string{"id": e.Check},
+			"properties": properties,
+		})
+	}
+	return map[string]any{
+		"executionSuccessful":        successful,
+		"toolExecutionNotifications": notifications,
+	}
+}
+
+func describeSelection(sel model.ProjectSelection) string {
+	var parts []string
+	for _, p := range []struct {
+		name   string
+		values []string
+	}{
+		{"include", sel.Include},
+		{"exclude", sel.Exclude},
+		{"labels", sel.Labels},
+		{"states", sel.States},
+	} {
+		if len(p.values) > 0 {
+			parts = append(parts, p.name+"="+strings.Join(p.values, ","))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// sarifBaselineState maps baseline states onto SARIF's. Fixed findings are
+// not emitted as "absent" results because code scanning would open alerts
+// for them.
+func sarifBaselineState(state string) string {
+	switch state {
+	case model.BaselineNew:
+		return "new"
+	case model.BaselineExisting:
+		return "unchanged"
 	default:
-		return 1
+		return ""
 	}
 }
 
@@ -153,3 +291,27 @@ func sarifLevel(s model.Severity) string {
 		return "note"
 	}
 }
+
+func describeSources(sources []model.Source) string {
+	parts := make([]string, 0, len(sources))
+	for _, src := range sources {
+		var label string
+		switch {
+		case src.Organization != "":
+			label = "organization " + src.Organization
+		case src.Folder != "":
+			label = "folder " + src.Folder
+		case src.Project != "":
+			label = "project " + src.Project
+		case src.Repo != "":
+			label = "repo " + src.Repo
+		default:
+			label = "scan"
+		}
+		if src.File != "" {
+			label += " (" + src.File + ")"
+		}
+		parts = append(parts, "`"+label+"`")
+	}
+	return strings.Join(parts, ", ")
+}
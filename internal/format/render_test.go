@@ -1,10 +1,12 @@
 package format
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Andrei-Barwood/gcpsec/internal/baseline"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 )
 
@@ -58,3 +60,114 @@ func TestSARIFContainsRule(t *testing.T) {
 		t.Fatalf("sarif did not include rule id")
 	}
 }
+
+func TestSARIFReportsCheckExecutions(t *testing.T) {
+	r := model.ScanResult{
+		Executions: []model.CheckExecution{
+			{Check: "api-keys", Target: "projects/demo", Status: model.CheckPassed, Reason: "no_findings", ResourcesEvaluated: 3},
+			{Check: "billing-budgets", Target: "projects/demo", Status: model.CheckErrored, Reason: "check_error", ErrorCategory: "permission_denied", Message: "PERMISSION_DENIED"},
+		},
+	}
+
+	b, err := SARIF(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc struct {
+		Runs []struct {
+			Invocations []struct {
+				ExecutionSuccessful        bool `json:"executionSuccessful"`
+				ToolExecutionNotifications []struct {
+					Level      string            `json:"level"`
+					Descriptor map[string]string `json:"descriptor"`
+					Properties map[string]any    `json:"properties"`
+				} `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid sarif: %v", err)
+	}
+	inv := doc.Runs[0].Invocations
+	if len(inv) != 1 || inv[0].ExecutionSuccessful {
+		t.Fatalf("expected one unsuccessful invocation, got %+v", inv)
+	}
+	notes := inv[0].ToolExecutionNotifications
+	if len(notes) != 2 || notes[1].Level != "error" || notes[1].Descriptor["id"] != "billing-budgets" || notes[1].Properties["errorCategory"] != "permission_denied" {
+		t.Fatalf("unexpected notifications: %+v", notes)
+	}
+
+	md, err := Markdown(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(md), "| `billing-budgets` | projects/demo | errored | check_error | permission_denied | 0 | 0 | 0ms |") {
+		t.Fatalf("markdown did not include execution table:\n%s", md)
+	}
+}
+
+func TestSARIFSetsBaselineState(t *testing.T) {
+	r := model.ScanResult{
+		Findings: []model.Finding{
+			{ID: "a", Severity: model.SeverityHigh, BaselineState: model.BaselineNew, Fingerprint: "0123abcd"},
+			{ID: "b", Severity: model.SeverityLow, BaselineState: model.BaselineExisting},
+		},
+		Fixed: []model.Finding{{ID: "c", Severity: model.SeverityHigh, BaselineState: model.BaselineFixed}},
+	}
+
+	b, err := SARIF(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc struct {
+		Runs []struct {
+			Results []struct {
+				RuleID              string            `json:"ruleId"`
+				BaselineState       string            `json:"baselineState"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("invalid sarif: %v", err)
+	}
+	results := doc.Runs[0].Results
+	if len(results) != 2 || results[0].BaselineState != "new" || results[1].BaselineState != "unchanged" {
+		t.Fatalf("unexpected baseline states: %+v", results)
+	}
+	if results[0].PartialFingerprints[model.FingerprintVersion] != "0123abcd" || results[1].PartialFingerprints != nil {
+		t.Fatalf("unexpected partial fingerprints: %+v", results)
+	}
+}
+
+func TestDiffMarkdownListsChanges(t *testing.T) {
+	d := baseline.Diff{
+		Added:    []model.Finding{{ID: "a", Severity: model.SeverityHigh, Summary: "New key", Resource: "keys/2"}},
+		Resolved: []model.Finding{{ID: "b", Severity: model.SeverityLow, Summary: "Old key"}},
+		Changed: []baseline.Change{{
+			Fields: []string{"severity"},
+			Old:    model.Finding{Severity: model.SeverityMedium},
+			New:    model.Finding{Severity: model.SeverityHigh, Summary: "OS Login"},
+		}},
+	}
+
+	b, err := DiffMarkdown(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := string(b)
+	for _, want := range []string{"- **[HIGH]** New key — `keys/2`", "- ~~[LOW] Old key~~", "- [MEDIUM → HIGH] OS Login: severity"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestSparklineScalesToMaximum(t *testing.T) {
+	if got := Sparkline([]int{0, 2, 4, 8}); got != "▁▂▄█" {
+		t.Fatalf("unexpected sparkline: %q", got)
+	}
+	if got := Sparkline([]int{0, 0}); got != "▁▁" {
+		t.Fatalf("unexpected flat sparkline: %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/history"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+var TrendSeverities = []model.Severity{
+	model.SeverityCritical,
+	model.SeverityHigh,
+	model.SeverityMedium,
+	model.SeverityLow,
+	model.SeverityInfo,
+}
+
+// TrendCSV writes one row per scan: totals, one column per severity and one
+// per check.
+func TrendCSV(t history.Trend) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	checks := t.Checks()
+
+	header := []string{"generated_at", "total"}
+	for _, sev := range TrendSeverities {
+		header = append(header, string(sev))
+	}
+	header = append(header, checks...)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, p := range t.Points {
+		row := []string{p.GeneratedAt.UTC().Format(time.RFC3339), strconv.Itoa(p.Total)}
+		for _, sev := range TrendSeverities {
+			row = append(row, strconv.Itoa(p.BySeverity[sev]))
+		}
+		for _, check := range checks {
+			row = append(row, strconv.Itoa(p.ByCheck[check]))
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func TrendMarkdown(t history.Trend) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("# gcpsec trend\n\n")
+	fmt.Fprintf(&b, "- Scope: `%s`\n", t.Scope)
+	fmt.Fprintf(&b, "- Scans: `%d`\n", len(t.Points))
+	if n := len(t.Points); n > 0 {
+		fmt.Fprintf(&b, "- From `%s` to `%s`\n",
+			t.Points[0].GeneratedAt.Format("2006-01-02 15:04:05 UTC"),
+			t.Points[n-1].GeneratedAt.Format("2006-01-02 15:04:05 UTC"))
+	}
+	fmt.Fprintf(&b, "- Remediated: `%d`, still open: `%d`\n", len(t.Remediations), t.Open)
+	if len(t.Remediations) > 0 {
+		fmt.Fprintf(&b, "- Mean time to remediate: `%s`\n", Age(t.MeanTimeToRemediate()))
+	}
+	b.WriteString("\n")
+
+	if len(t.Points) == 0 {
+		b.WriteString("No scans in history.\n")
+		return []byte(b.String()), nil
+	}
+
+	b.WriteString("## By severity\n\n")
+	b.WriteString("| Severity | Trend | First | Last |\n")
+	b.WriteString("|---|---|---:|---:|\n")
+	series := func(value func(history.Point) int) []int {
+		out := make([]int, len(t.Points))
+		for i, p := range t.Points {
+			out[i] = value(p)
+		}
+		return out
+	}
+	trendRow := func(name string, values []int) {
+		fmt.Fprintf(&b, "| %s | `%s` | %d | %d |\n", name, Sparkline(values), values[0], values[len(values)-1])
+	}
+	trendRow("total", series(func(p history.Point) int { return p.Total }))
+	for _, sev := range TrendSeverities {
+		trendRow(string(sev), series(func(p history.Point) int { return p.BySeverity[sev] }))
+	}
+
+	if checks := t.Checks(); len(checks) > 0 {
+		b.WriteString("\n## By check\n\n")
+		b.WriteString("| Check | Trend | First | Last |\n")
+		b.WriteString("|---|---|---:|---:|\n")
+		for _, check := range checks {
+			trendRow(check, series(func(p history.Point) int { return p.ByCheck[check] }))
+		}
+	}
+
+	if len(t.Remediations) > 0 {
+		b.WriteString("\n## Time to remediate\n\n")
+		b.WriteString("| Finding | Resource | First seen | Resolved | Open for |\n")
+		b.WriteString("|---|---|---|---|---:|\n")
+		for _, r := range t.Remediations {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n",
+				r.ID, r.Resource,
+				r.FirstSeen.Format("2006-01-02"), r.ResolvedAt.Format("2006-01-02"), Age(r.Duration))
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline scales values between zero and their maximum onto eight bar
+// heights.
+func Sparkline(values []int) string {
+	top := 0
+	for _, v := range values {
+		if v > top {
+			top = v
+		}
+	}
+	out := make([]rune, len(values))
+	for i, v := range values {
+		idx := 0
+		if top > 0 {
+			idx = v * (len(sparkBars) - 1) / top
+		}
+		out[i] = sparkBars[idx]
+	}
+	return string(out)
+}
+
+// Age renders a duration in days and hours, or minutes when shorter.
+func Age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		days := d / (24 * time.Hour)
+		hours := (d % (24 * time.Hour)) / time.Hour
+		if hours == 0 {
+			return fmt.Sprintf("%dd", days)
+		}
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
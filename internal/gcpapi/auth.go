@@ -0,0 +1,246 @@
+package gcpapi
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	defaultTokenURL    = "https://oauth2.googleapis.com/token"
+	metadataTokenURL   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type StaticToken string
+
+func (t StaticToken) Token(context.Context) (string, error) {
+	if t == "" {
+		return "", errors.New("empty access token")
+	}
+	return string(t), nil
+}
+
+type credentialsFile struct {
+	Type         string `json:"type"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// adcTokenSource resolves Application Default Credentials on first use and
+// caches the access token until shortly before it expires.
+type adcTokenSource struct {
+	http *http.Client
+
+	mu      sync.Mutex
+	fetch   func(ctx context.Context) (string, time.Duration, error)
+	token   string
+	expires time.Time
+}
+
+func DefaultTokenSource(httpClient *http.Client) TokenSource {
+	if tok := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); tok != "" {
+		return StaticToken(tok)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &adcTokenSource{http: httpClient}
+}
+
+func (a *adcTokenSource) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Now().Before(a.expires) {
+		return a.token, nil
+	}
+	if a.fetch == nil {
+		fetch, err := a.resolve()
+		if err != nil {
+			return "", err
+		}
+		a.fetch = fetch
+	}
+
+	tok, ttl, err := a.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	a.token = tok
+	a.expires = time.Now().Add(ttl - time.Minute)
+	return tok, nil
+}
+
+func (a *adcTokenSource) resolve() (func(context.Context) (string, time.Duration, error), error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = wellKnownCredentialsPath()
+		if _, err := os.Stat(path); err != nil {
+			return a.metadataToken, nil
+		}
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read application default credentials: %w", err)
+	}
+	var creds credentialsFile
+	if err := json.Unmarshal(buf, &creds); err != nil {
+		return nil, fmt.Errorf("parse application default credentials %s: %w", path, err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = defaultTokenURL
+	}
+
+	switch creds.Type {
+	case "authorized_user":
+		return func(ctx context.Context) (string, time.Duration, error) {
+			return a.exchange(ctx, creds.TokenURI, url.Values{
+				"grant_type":    {"refresh_token"},
+				"client_id":     {creds.ClientID},
+				"client_secret": {creds.ClientSecret},
+				"refresh_token": {creds.RefreshToken},
+			})
+		}, nil
+	case "service_account":
+		key, err := parsePrivateKey(creds.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) (string, time.Duration, error) {
+			assertion, err := signJWT(creds, key, time.Now())
+			if err != nil {
+				return "", 0, err
+			}
+			return a.exchange(ctx, creds.TokenURI, url.Values{
+				"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+				"assertion":  {assertion},
+			})
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credential type %q in %s; set GOOGLE_OAUTH_ACCESS_TOKEN or use the gcloud backend", creds.Type, path)
+	}
+}
+
+func (a *adcTokenSource) exchange(ctx context.Context, tokenURL string, form url.Values) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return a.doToken(req)
+}
+
+func (a *adcTokenSource) metadataToken(ctx context.Context) (string, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	tok, ttl, err := a.doToken(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("no application default credentials found and metadata server unavailable: %w", err)
+	}
+	return tok, ttl, nil
+}
+
+func (a *adcTokenSource) doToken(req *http.Request) (string, time.Duration, error) {
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", 0, fmt.Errorf("token endpoint returned %s: %s %s", resp.Status, body.Error, body.Description)
+	}
+	return body.AccessToken, time.Duration(body.ExpiresIn) * time.Second, nil
+}
+
+func wellKnownCredentialsPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("service account private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes); rsaErr == nil {
+			return rsaKey, nil
+		}
+		return nil, fmt.Errorf("parse service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account private key is not RSA")
+	}
+	return key, nil
+}
+
+func signJWT(creds credentialsFile, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": creds.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   creds.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
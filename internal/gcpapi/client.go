@@ -0,0 +1,307 @@
+package gcpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/ratelimit"
+)
+
+type Endpoints struct {
+	APIKeys           string
+	IAM               string
+	OrgPolicy         string
+	EssentialContacts string
+	ResourceManager   string
+}
+
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		APIKeys:           "https://apikeys.googleapis.com",
+		IAM:               "https://iam.googleapis.com",
+		OrgPolicy:         "https://orgpolicy.googleapis.com",
+		EssentialContacts: "https://essentialcontacts.googleapis.com",
+		ResourceManager:   "https://cloudresourcemanager.googleapis.com",
+	}
+}
+
+// SingleEndpoint routes every API to the same base URL, which is how tests
+// point the client at an httptest server.
+func SingleEndpoint(base string) Endpoints {
+	base = strings.TrimRight(base, "/")
+	return Endpoints{APIKeys: base, IAM: base, OrgPolicy: base, EssentialContacts: base, ResourceManager: base}
+}
+
+type Client struct {
+	HTTP      *http.Client
+	Tokens    TokenSource
+	Endpoints Endpoints
+	UserAgent string
+	Limiter   *ratelimit.Limiter
+	Backoff   ratelimit.Backoff
+}
+
+func New(tokens TokenSource) *Client {
+	return &Client{
+		HTTP:      http.DefaultClient,
+		Tokens:    tokens,
+		Endpoints: DefaultEndpoints(),
+		UserAgent: "gcpsec",
+		Backoff:   ratelimit.DefaultBackoff(),
+	}
+}
+
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string
+	Reason     string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: HTTPError %d", e.Method, e.URL, e.StatusCode)
+	if e.Status != "" {
+		msg += " " + e.Status
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func IsQuotaExceeded(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusTooManyRequests || apiErr.Status == "RESOURCE_EXHAUSTED")
+}
+
+func (c *Client) APIKeys(ctx context.Context, project string) ([]APIKey, error) {
+	u := fmt.Sprintf("%s/v2/projects/%s/locations/global/keys", c.Endpoints.APIKeys, url.PathEscape(project))
+	return list[APIKey](ctx, c, u, nil, "keys")
+}
+
+func (c *Client) ServiceAccounts(ctx context.Context, project string) ([]ServiceAccount, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/serviceAccounts", c.Endpoints.IAM, url.PathEscape(project))
+	return list[ServiceAccount](ctx, c, u, nil, "accounts")
+}
+
+func (c *Client) ServiceAccountKeys(ctx context.Context, project, email string) ([]ServiceAccountKey, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/serviceAccounts/%s/keys", c.Endpoints.IAM, url.PathEscape(project), url.PathEscape(email))
+	return list[ServiceAccountKey](ctx, c, u, url.Values{"keyTypes": {"USER_MANAGED"}}, "keys")
+}
+
+func (c *Client) OrgPolicy(ctx context.Context, parent, constraint string, effective bool) (OrgPolicy, error) {
+	u := fmt.Sprintf("%s/v2/%s/policies/%s", c.Endpoints.OrgPolicy, parent, url.PathEscape(strings.TrimPrefix(constraint, "constraints/")))
+	if effective {
+		u += ":getEffectivePolicy"
+	}
+	var policy OrgPolicy
+	if err := c.do(ctx, http.MethodGet, u, nil, &policy); err != nil {
+		if IsNotFound(err) {
+			return OrgPolicy{}, nil
+		}
+		return OrgPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (c *Client) ComputeContacts(ctx context.Context, project, category string) ([]Contact, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s/contacts:compute", c.Endpoints.EssentialContacts, url.PathEscape(project))
+	return list[Contact](ctx, c, u, url.Values{"notificationCategories": {category}}, "contacts")
+}
+
+func (c *Client) Ancestry(ctx context.Context, project string) ([]map[string]any, error) {
+	u := fmt.Sprintf("%s/v1/projects/%s:getAncestry", c.Endpoints.ResourceManager, url.PathEscape(project))
+	var resp struct {
+		Ancestor []struct {
+			ResourceID map[string]any `json:"resourceId"`
+		} `json:"ancestor"`
+	}
+	if err := c.do(ctx, http.MethodPost, u, map[string]any{}, &resp); err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, 0, len(resp.Ancestor))
+	for _, a := range resp.Ancestor {
+		out = append(out, a.ResourceID)
+	}
+	return out, nil
+}
+
+func (c *Client) IAMPolicy(ctx context.Context, resource string) (map[string]any, error) {
+	u := fmt.Sprintf("%s/v3/%s:getIamPolicy", c.Endpoints.ResourceManager, resource)
+	body := map[string]any{"options": map[string]any{"requestedPolicyVersion": 3}}
+	var policy map[string]any
+	if err := c.do(ctx, http.MethodPost, u, body, &policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// TestIAMPermissions returns the subset of permissions the caller holds on a
+// project, folder or organization.
+func (c *Client) TestIAMPermissions(ctx context.Context, resource string, permissions []string) ([]string, error) {
+	u := fmt.Sprintf("%s/v3/%s:testIamPermissions", c.Endpoints.ResourceManager, resource)
+	var resp struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := c.do(ctx, http.MethodPost, u, map[string]any{"permissions": permissions}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+func (c *Client) Projects(ctx context.Context, parent string) ([]map[string]any, error) {
+	return list[map[string]any](ctx, c, c.Endpoints.ResourceManager+"/v3/projects", url.Values{"parent": {parent}}, "projects")
+}
+
+func (c *Client) Folders(ctx context.Context, parent string) ([]map[string]any, error) {
+	return list[map[string]any](ctx, c, c.Endpoints.ResourceManager+"/v3/folders", url.Values{"parent": {parent}}, "folders")
+}
+
+func list[T any](ctx context.Context, c *Client, base string, query url.Values, field string) ([]T, error) {
+	out := make([]T, 0)
+	pageToken := ""
+	for {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+		u := base
+		if len(q) > 0 {
+			u += "?" + q.Encode()
+		}
+
+		var page map[string]json.RawMessage
+		if err := c.do(ctx, http.MethodGet, u, nil, &page); err != nil {
+			return nil, err
+		}
+		if items, ok := page[field]; ok {
+			var batch []T
+			if err := Decode(items, &batch); err != nil {
+				return nil, fmt.Errorf("%s: %w", u, err)
+			}
+			out = append(out, batch...)
+		}
+		pageToken = ""
+		if next, ok := page["nextPageToken"]; ok {
+			if err := json.Unmarshal(next, &pageToken); err != nil {
+				return nil, fmt.Errorf("%s: invalid nextPageToken: %w", u, err)
+			}
+		}
+		if pageToken == "" {
+			return out, nil
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, u string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = buf
+	}
+	return ratelimit.Retry(ctx, c.Backoff, IsQuotaExceeded, func() error {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+		return c.send(ctx, method, u, payload, out)
+	})
+}
+
+func (c *Client) send(ctx context.Context, method, u string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.Tokens != nil {
+		token, err := c.Tokens.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("obtain access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(method, u, resp.StatusCode, payload)
+	}
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return nil
+	}
+	if err := Decode(payload, out); err != nil {
+		return fmt.Errorf("%s %s: %w", method, u, err)
+	}
+	return nil
+}
+
+func newAPIError(method, u string, code int, payload []byte) *APIError {
+	apiErr := &APIError{Method: method, URL: u, StatusCode: code}
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				Reason string `json:"reason"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(payload, &body) == nil {
+		apiErr.Status = body.Error.Status
+		apiErr.Message = body.Error.Message
+		for _, d := range body.Error.Details {
+			if d.Reason != "" {
+				apiErr.Reason = d.Reason
+				break
+			}
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(payload))
+	}
+	return apiErr
+}
@@ -0,0 +1,143 @@
+package gcpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListFollowsPagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+		if r.URL.Path != "/v1/projects/demo/serviceAccounts" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("pageToken") {
+		case "":
+			w.Write([]byte(`{"accounts": [{"email": "a@demo.iam.gserviceaccount.com"}], "nextPageToken": "p2"}`))
+		case "p2":
+			w.Write([]byte(`{"accounts": [{"email": "b@demo.iam.gserviceaccount.com"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := New(StaticToken("test-token"))
+	c.Endpoints = SingleEndpoint(srv.URL)
+
+	accounts, err := c.ServiceAccounts(context.Background(), "demo")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(accounts) != 2 || accounts[1].Email != "b@demo.iam.gserviceaccount.com" {
+		t.Fatalf("unexpected accounts: %+v", accounts)
+	}
+}
+
+func TestAPIErrorCarriesStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "status": "PERMISSION_DENIED", "message": "caller lacks apikeys.keys.list", "details": [{"reason": "IAM_PERMISSION_DENIED"}]}}`))
+	}))
+	defer srv.Close()
+
+	c := New(StaticToken("t"))
+	c.Endpoints = SingleEndpoint(srv.URL)
+
+	_, err := c.APIKeys(context.Background(), "demo")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "PERMISSION_DENIED") || !strings.Contains(err.Error(), "HTTPError 403") {
+		t.Fatalf("error should carry the API status, got %v", err)
+	}
+}
+
+func TestOrgPolicyNotFoundIsEmpty(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/projects/demo/policies/iam.disableServiceAccountKeyCreation:getEffectivePolicy" {
+			w.Write([]byte(`{"name": "projects/demo/policies/iam.disableServiceAccountKeyCreation", "spec": {"rules": [{"enforce": true}]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": 404, "status": "NOT_FOUND"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(StaticToken("t"))
+	c.Endpoints = SingleEndpoint(srv.URL)
+
+	effective, err := c.OrgPolicy(context.Background(), "projects/demo", "constraints/iam.disableServiceAccountKeyCreation", true)
+	if err != nil || effective.Spec == nil {
+		t.Fatalf("expected effective policy, got %+v (%v)", effective, err)
+	}
+	local, err := c.OrgPolicy(context.Background(), "projects/demo", "constraints/iam.disableServiceAccountKeyCreation", false)
+	if err != nil || local.IsSet() {
+		t.Fatalf("expected empty policy for NOT_FOUND, got %+v (%v)", local, err)
+	}
+}
+
+func TestServiceAccountCredentialsExchangeJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Form.Get("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("unexpected grant type %q", got)
+		}
+		if parts := strings.Split(r.Form.Get("assertion"), "."); len(parts) != 3 {
+			t.Errorf("assertion is not a JWT: %q", r.Form.Get("assertion"))
+		}
+		w.Write([]byte(`{"access_token": "sa-token", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "scanner@demo.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, creds, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	ts := DefaultTokenSource(srv.Client())
+	for i := 0; i < 2; i++ {
+		tok, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("token failed: %v", err)
+		}
+		if tok != "sa-token" {
+			t.Fatalf("unexpected token %q", tok)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected cached token, token endpoint called %d times", calls)
+	}
+}
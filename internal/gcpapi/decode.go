@@ -0,0 +1,124 @@
+package gcpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaError reports a response that does not decode into the expected
+// resource type, typically because a required field was renamed or removed
+// upstream or a field changed type.
+type SchemaError struct {
+	Type string
+	Err  error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("response does not match the %s schema: %v", e.Type, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+func IsSchemaError(err error) bool {
+	var schemaErr *SchemaError
+	return errors.As(err, &schemaErr)
+}
+
+// Decode unmarshals data into out. Fields the type does not know are
+// ignored, since Google adds output fields routinely; a field whose type
+// changed or a required one (no omitempty in its tag) that is missing is a
+// SchemaError.
+func Decode(data []byte, out any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(out); err != nil {
+		return &SchemaError{Type: typeName(out), Err: err}
+	}
+	if dec.More() {
+		return &SchemaError{Type: typeName(out), Err: errors.New("unexpected trailing data")}
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &SchemaError{Type: typeName(out), Err: err}
+	}
+	if err := checkRequired(reflect.TypeOf(out), raw, "$"); err != nil {
+		return &SchemaError{Type: typeName(out), Err: err}
+	}
+	return nil
+}
+
+// checkRequired walks a decoded JSON value alongside the Go type it was
+// decoded into and reports the first required field that is absent.
+func checkRequired(t reflect.Type, v any, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		items, _ := v.([]any)
+		for i, item := range items {
+			if err := checkRequired(t.Elem(), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, _ := v.(map[string]any)
+		for k, item := range obj {
+			if err := checkRequired(t.Elem(), item, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			value, present := obj[name]
+			if !present {
+				if !strings.Contains(opts, "omitempty") {
+					return fmt.Errorf("missing required field %s.%s", path, name)
+				}
+				continue
+			}
+			if err := checkRequired(field.Type, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeValue re-encodes an already parsed JSON value and decodes it into
+// out with the same checks as Decode.
+func DecodeValue(v any, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return Decode(data, out)
+}
+
+func typeName(out any) string {
+	t := reflect.TypeOf(out)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "response"
+	}
+	return t.Name()
+}
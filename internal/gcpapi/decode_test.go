@@ -0,0 +1,25 @@
+package gcpapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeChecksRequiredFieldsAndTypes(t *testing.T) {
+	var keys []APIKey
+	if err := Decode([]byte(`[{"name": "k1", "newField": true}]`), &keys); err != nil || keys[0].Name != "k1" {
+		t.Fatalf("expected unknown fields to be ignored, got %+v (%v)", keys, err)
+	}
+
+	cases := map[string]string{
+		`[{"displayName": "k1"}]`: "missing required field $[0].name",
+		`[{"name": "k1", "restrictions": {"apiTargets": [{"methods": []}]}}]`: "missing required field $[0].restrictions.apiTargets[0].service",
+		`[{"name": "k1", "restrictions": []}]`:                                "cannot unmarshal array",
+	}
+	for payload, want := range cases {
+		err := Decode([]byte(payload), &keys)
+		if !IsSchemaError(err) || !strings.Contains(err.Error(), want) {
+			t.Errorf("Decode(%s) = %v, want schema error containing %q", payload, err, want)
+		}
+	}
+}
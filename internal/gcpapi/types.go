@@ -0,0 +1,160 @@
+package gcpapi
+
+type APIKey struct {
+	Name                string              `json:"name"`
+	UID                 string              `json:"uid,omitempty"`
+	DisplayName         string              `json:"displayName,omitempty"`
+	KeyString           string              `json:"keyString,omitempty"`
+	CreateTime          string              `json:"createTime,omitempty"`
+	UpdateTime          string              `json:"updateTime,omitempty"`
+	DeleteTime          string              `json:"deleteTime,omitempty"`
+	Annotations         map[string]string   `json:"annotations,omitempty"`
+	Restrictions        *APIKeyRestrictions `json:"restrictions,omitempty"`
+	Etag                string              `json:"etag,omitempty"`
+	ServiceAccountEmail string              `json:"serviceAccountEmail,omitempty"`
+}
+
+type APIKeyRestrictions struct {
+	BrowserKeyRestrictions *BrowserKeyRestrictions `json:"browserKeyRestrictions,omitempty"`
+	ServerKeyRestrictions  *ServerKeyRestrictions  `json:"serverKeyRestrictions,omitempty"`
+	AndroidKeyRestrictions *AndroidKeyRestrictions `json:"androidKeyRestrictions,omitempty"`
+	IOSKeyRestrictions     *IOSKeyRestrictions     `json:"iosKeyRestrictions,omitempty"`
+	APITargets             []APITarget             `json:"apiTargets,omitempty"`
+}
+
+type BrowserKeyRestrictions struct {
+	AllowedReferrers []string `json:"allowedReferrers,omitempty"`
+}
+
+type ServerKeyRestrictions struct {
+	AllowedIPs []string `json:"allowedIps,omitempty"`
+}
+
+type AndroidKeyRestrictions struct {
+	AllowedApplications []AndroidApplication `json:"allowedApplications,omitempty"`
+}
+
+type AndroidApplication struct {
+	SHA1Fingerprint string `json:"sha1Fingerprint,omitempty"`
+	PackageName     string `json:"packageName,omitempty"`
+}
+
+type IOSKeyRestrictions struct {
+	AllowedBundleIDs []string `json:"allowedBundleIds,omitempty"`
+}
+
+type APITarget struct {
+	Service string   `json:"service"`
+	Methods []string `json:"methods,omitempty"`
+}
+
+func (r *APIKeyRestrictions) HasAPITargets() bool {
+	return r != nil && len(r.APITargets) > 0
+}
+
+func (r *APIKeyRestrictions) HasEnvironmentRestrictions() bool {
+	if r == nil {
+		return false
+	}
+	return (r.BrowserKeyRestrictions != nil && len(r.BrowserKeyRestrictions.AllowedReferrers) > 0) ||
+		(r.ServerKeyRestrictions != nil && len(r.ServerKeyRestrictions.AllowedIPs) > 0) ||
+		(r.AndroidKeyRestrictions != nil && len(r.AndroidKeyRestrictions.AllowedApplications) > 0) ||
+		(r.IOSKeyRestrictions != nil && len(r.IOSKeyRestrictions.AllowedBundleIDs) > 0)
+}
+
+type ServiceAccount struct {
+	Name           string `json:"name,omitempty"`
+	ProjectID      string `json:"projectId,omitempty"`
+	UniqueID       string `json:"uniqueId,omitempty"`
+	Email          string `json:"email"`
+	DisplayName    string `json:"displayName,omitempty"`
+	Etag           string `json:"etag,omitempty"`
+	Description    string `json:"description,omitempty"`
+	OAuth2ClientID string `json:"oauth2ClientId,omitempty"`
+	Disabled       bool   `json:"disabled,omitempty"`
+}
+
+type ServiceAccountKey struct {
+	Name            string              `json:"name"`
+	PrivateKeyType  string              `json:"privateKeyType,omitempty"`
+	KeyAlgorithm    string              `json:"keyAlgorithm,omitempty"`
+	PrivateKeyData  string              `json:"privateKeyData,omitempty"`
+	PublicKeyData   string              `json:"publicKeyData,omitempty"`
+	ValidAfterTime  string              `json:"validAfterTime,omitempty"`
+	ValidBeforeTime string              `json:"validBeforeTime,omitempty"`
+	KeyOrigin       string              `json:"keyOrigin,omitempty"`
+	KeyType         string              `json:"keyType,omitempty"`
+	Disabled        bool                `json:"disabled,omitempty"`
+	DisableReason   string              `json:"disableReason,omitempty"`
+	ExtendedStatus  []KeyExtendedStatus `json:"extendedStatus,omitempty"`
+	Contact         string              `json:"contact,omitempty"`
+	Description     string              `json:"description,omitempty"`
+	Creator         string              `json:"creator,omitempty"`
+}
+
+type KeyExtendedStatus struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type OrgPolicy struct {
+	Name       string               `json:"name,omitempty"`
+	Spec       *PolicySpec          `json:"spec,omitempty"`
+	DryRunSpec *PolicySpec          `json:"dryRunSpec,omitempty"`
+	Alternate  *AlternatePolicySpec `json:"alternate,omitempty"`
+	Etag       string               `json:"etag,omitempty"`
+}
+
+type AlternatePolicySpec struct {
+	Launch string      `json:"launch,omitempty"`
+	Spec   *PolicySpec `json:"spec,omitempty"`
+}
+
+type PolicySpec struct {
+	Etag              string       `json:"etag,omitempty"`
+	UpdateTime        string       `json:"updateTime,omitempty"`
+	Rules             []PolicyRule `json:"rules,omitempty"`
+	InheritFromParent bool         `json:"inheritFromParent,omitempty"`
+	Reset             bool         `json:"reset,omitempty"`
+}
+
+type PolicyRule struct {
+	Values     *PolicyValues  `json:"values,omitempty"`
+	AllowAll   bool           `json:"allowAll,omitempty"`
+	DenyAll    bool           `json:"denyAll,omitempty"`
+	Enforce    bool           `json:"enforce,omitempty"`
+	Condition  *Expr          `json:"condition,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+type PolicyValues struct {
+	AllowedValues []string `json:"allowedValues,omitempty"`
+	DeniedValues  []string `json:"deniedValues,omitempty"`
+}
+
+type Expr struct {
+	Expression  string `json:"expression,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Location    string `json:"location,omitempty"`
+}
+
+func (p OrgPolicy) Rules() []PolicyRule {
+	if p.Spec == nil {
+		return nil
+	}
+	return p.Spec.Rules
+}
+
+func (p OrgPolicy) IsSet() bool {
+	return p.Spec != nil && (len(p.Spec.Rules) > 0 || p.Spec.Reset)
+}
+
+type Contact struct {
+	Name                              string   `json:"name"`
+	Email                             string   `json:"email"`
+	NotificationCategorySubscriptions []string `json:"notificationCategorySubscriptions,omitempty"`
+	LanguageTag                       string   `json:"languageTag,omitempty"`
+	ValidationState                   string   `json:"validationState,omitempty"`
+	ValidateTime                      string   `json:"validateTime,omitempty"`
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+	"github.com/Andrei-Barwood/gcpsec/internal/report"
+)
+
+const DefaultDir = ".gcpsec/history"
+
+// Scope names what a scan covered: projects/<id>, organizations/<id>,
+// folders/<id>, or repo for local-only scans. Trends are only meaningful
+// between scans of the same scope.
+func Scope(result model.ScanResult) string {
+	switch {
+	case result.Organization != "":
+		return "organizations/" + result.Organization
+	case result.Folder != "":
+		return "folders/" + result.Folder
+	case result.Project != "":
+		return "projects/" + result.Project
+	default:
+		return "repo"
+	}
+}
+
+// Save stores a scan in dir under a name built from its timestamp and
+// scope, so a directory listing sorts chronologically.
+func Save(dir string, result model.ScanResult) (string, error) {
+	name := fmt.Sprintf("%s-%s.json",
+		result.GeneratedAt.UTC().Format("20060102T150405Z"),
+		strings.ReplaceAll(Scope(result), "/", "-"),
+	)
+	payload, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	return path, report.Save(path, payload)
+}
+
+// Load reads every scan in dir, oldest first. When scope is not empty only
+// scans of that scope are returned.
+func Load(dir, scope string) ([]model.ScanResult, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var scans []model.ScanResult
+	for _, path := range paths {
+		scan, err := report.LoadScan(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if scope != "" && Scope(scan) != scope {
+			continue
+		}
+		scans = append(scans, scan)
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].GeneratedAt.Before(scans[j].GeneratedAt) })
+	return scans, nil
+}
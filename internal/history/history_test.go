@@ -0,0 +1,89 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestSaveAndLoadKeepsScopesApart(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scans := []model.ScanResult{
+		{GeneratedAt: base.Add(48 * time.Hour), Project: "demo"},
+		{GeneratedAt: base, Project: "demo"},
+		{GeneratedAt: base.Add(24 * time.Hour), Project: "other"},
+	}
+	for _, scan := range scans {
+		if _, err := Save(dir, scan); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	got, err := Load(dir, "projects/demo")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != 2 || !got[0].GeneratedAt.Equal(base) || !got[1].GeneratedAt.Equal(base.Add(48*time.Hour)) {
+		t.Fatalf("expected both demo scans oldest first, got %+v", got)
+	}
+	all, err := Load(dir, "")
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected every scan without a scope, got %d (%v)", len(all), err)
+	}
+}
+
+func TestComputeCountsAndTimeToRemediate(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	key := model.Finding{ID: "gcp.api_key.unrestricted", Check: "API Keys", Resource: "maps", Severity: model.SeverityHigh}
+	stale := model.Finding{ID: "gcp.sa_key.stale_review", Check: "Service Account Keys", Resource: "keys/1", Severity: model.SeverityMedium}
+	expiry := model.Finding{ID: "gcp.sa_key.no_expiry", Check: "Service Account Keys", Resource: "keys/2", Severity: model.SeverityHigh}
+	scans := []model.ScanResult{
+		{GeneratedAt: base, Findings: []model.Finding{key, stale}},
+		{GeneratedAt: base.Add(24 * time.Hour), Findings: []model.Finding{key, stale, expiry}},
+		{GeneratedAt: base.Add(72 * time.Hour), Findings: []model.Finding{expiry}},
+		{GeneratedAt: base.Add(96 * time.Hour), Findings: []model.Finding{}},
+	}
+
+	tr := Compute("projects/demo", scans)
+	if len(tr.Points) != 4 || tr.Points[1].Total != 3 || tr.Points[1].BySeverity[model.SeverityHigh] != 2 || tr.Points[1].ByCheck["Service Account Keys"] != 2 {
+		t.Fatalf("unexpected points: %+v", tr.Points)
+	}
+	if len(tr.Remediations) != 3 || tr.Open != 0 {
+		t.Fatalf("expected three remediations and nothing open, got %+v (open %d)", tr.Remediations, tr.Open)
+	}
+	if tr.Remediations[0].ID != key.ID || tr.Remediations[0].Duration != 72*time.Hour {
+		t.Fatalf("unexpected first remediation: %+v", tr.Remediations[0])
+	}
+	if tr.Remediations[2].ID != expiry.ID || tr.Remediations[2].Duration != 72*time.Hour {
+		t.Fatalf("unexpected last remediation: %+v", tr.Remediations[2])
+	}
+	if mttr := tr.MeanTimeToRemediate(); mttr != 72*time.Hour {
+		t.Fatalf("expected 72h mean time to remediate, got %s", mttr)
+	}
+}
+
+func TestComputeKeepsFindingsOpenWhenTheirCheckDidNotRun(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	key := model.Finding{ID: "gcp.api_key.unrestricted", Check: "API Key Restrictions", Project: "demo", Resource: "keys/abc", Severity: model.SeverityHigh}
+	execution := func(status model.CheckStatus) []model.CheckExecution {
+		return []model.CheckExecution{{Check: "api-keys", Project: "demo", Status: status, FindingIDs: []string{key.ID}}}
+	}
+	scans := []model.ScanResult{
+		{GeneratedAt: base, Findings: []model.Finding{key}, Executions: execution(model.CheckFailed)},
+		{GeneratedAt: base.Add(24 * time.Hour), Findings: []model.Finding{}, Executions: execution(model.CheckErrored)},
+		{GeneratedAt: base.Add(48 * time.Hour), Findings: []model.Finding{}, Executions: []model.CheckExecution{{Check: "org-policies", Project: "demo", Status: model.CheckPassed, FindingIDs: []string{"gcp.org_policy.os_login"}}}},
+	}
+
+	tr := Compute("projects/demo", scans)
+	if len(tr.Remediations) != 0 || tr.Open != 1 {
+		t.Fatalf("expected the key to stay open while api-keys did not complete, got %+v (open %d)", tr.Remediations, tr.Open)
+	}
+
+	scans = append(scans, model.ScanResult{GeneratedAt: base.Add(72 * time.Hour), Findings: []model.Finding{}, Executions: execution(model.CheckPassed)})
+	tr = Compute("projects/demo", scans)
+	if len(tr.Remediations) != 1 || tr.Remediations[0].Duration != 72*time.Hour || tr.Open != 0 {
+		t.Fatalf("expected the key to be remediated by the passing scan, got %+v (open %d)", tr.Remediations, tr.Open)
+	}
+}
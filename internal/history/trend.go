@@ -0,0 +1,121 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/baseline"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+// Point summarizes the findings of one scan.
+type Point struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Total       int                    `json:"total"`
+	BySeverity  map[model.Severity]int `json:"by_severity"`
+	ByCheck     map[string]int         `json:"by_check"`
+}
+
+// Remediation is one stretch of time a finding was open, from the first scan
+// that reported it to the first later scan that evaluated its check and did
+// not report it.
+type Remediation struct {
+	Fingerprint string        `json:"fingerprint"`
+	ID          string        `json:"id"`
+	Check       string        `json:"check"`
+	Project     string        `json:"project,omitempty"`
+	Resource    string        `json:"resource,omitempty"`
+	FirstSeen   time.Time     `json:"first_seen"`
+	ResolvedAt  time.Time     `json:"resolved_at"`
+	Duration    time.Duration `json:"duration"`
+}
+
+type Trend struct {
+	Scope        string        `json:"scope"`
+	Points       []Point       `json:"points"`
+	Remediations []Remediation `json:"remediations"`
+	Open         int           `json:"open"`
+}
+
+// Compute builds a trend from scans of a single scope, oldest first.
+func Compute(scope string, scans []model.ScanResult) Trend {
+	t := Trend{Scope: scope, Points: []Point{}, Remediations: []Remediation{}}
+
+	type openFinding struct {
+		finding   model.Finding
+		firstSeen time.Time
+	}
+	open := map[string]openFinding{}
+	var order []string
+
+	for _, scan := range scans {
+		p := Point{GeneratedAt: scan.GeneratedAt, BySeverity: map[model.Severity]int{}, ByCheck: map[string]int{}}
+		present := map[string]bool{}
+		for _, f := range scan.Findings {
+			fp := baseline.Fingerprint(f)
+			if present[fp] {
+				continue
+			}
+			present[fp] = true
+			p.Total++
+			p.BySeverity[f.Severity]++
+			p.ByCheck[f.Check]++
+			if _, ok := open[fp]; !ok {
+				open[fp] = openFinding{finding: f, firstSeen: scan.GeneratedAt}
+				order = append(order, fp)
+			}
+		}
+		t.Points = append(t.Points, p)
+
+		remaining := order[:0]
+		for _, fp := range order {
+			o := open[fp]
+			if present[fp] || !scan.Evaluated(o.finding) {
+				remaining = append(remaining, fp)
+				continue
+			}
+			t.Remediations = append(t.Remediations, Remediation{
+				Fingerprint: fp,
+				ID:          o.finding.ID,
+				Check:       o.finding.Check,
+				Project:     o.finding.Project,
+				Resource:    o.finding.Resource,
+				FirstSeen:   o.firstSeen,
+				ResolvedAt:  scan.GeneratedAt,
+				Duration:    scan.GeneratedAt.Sub(o.firstSeen),
+			})
+			delete(open, fp)
+		}
+		order = remaining
+	}
+	t.Open = len(open)
+	return t
+}
+
+// MeanTimeToRemediate averages the duration of every resolved finding.
+func (t Trend) MeanTimeToRemediate() time.Duration {
+	if len(t.Remediations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, r := range t.Remediations {
+		total += r.Duration
+	}
+	return total / time.Duration(len(t.Remediations))
+}
+
+// Checks lists every check that reported a finding in any scan.
+func (t Trend) Checks() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range t.Points {
+		for check := range p.ByCheck {
+			if !seen[check] {
+				seen[check] = true
+				out = append(out, check)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
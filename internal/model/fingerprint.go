@@ -0,0 +1,80 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// FingerprintVersion names the fingerprint algorithm. It is emitted as the
+// SARIF partialFingerprints key and must change if Fingerprint ever does.
+const FingerprintVersion = "gcpsec/v1"
+
+// fingerprintIdentityKeys are the metadata keys that say what a finding is
+// about beyond its resource. Values that drift between scans (roles, dates,
+// etags, recommendation names) are deliberately left out.
+var fingerprintIdentityKeys = []string{
+	"secret_sha256",
+	"key_name",
+	"constraint",
+	"category",
+	"email",
+	"service",
+	"log_type",
+	"member",
+	"service_account",
+	"billing_account",
+	"budget",
+}
+
+// Fingerprint derives a stable identity for a finding from its rule ID,
+// project, normalized resource and check-specific identity metadata, so
+// the same issue can be matched across scans even when its description
+// changes.
+func Fingerprint(f Finding) string {
+	parts := []string{FingerprintVersion, f.ID, f.Project, NormalizeResource(f.Resource)}
+	for _, key := range fingerprintIdentityKeys {
+		if v, ok := f.Metadata[key]; ok && v != "" {
+			parts = append(parts, key+"="+NormalizeResource(v))
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizeResource strips the spellings a resource name can take in
+// different APIs: full resource names (//iam.googleapis.com/...), REST URLs,
+// Windows separators and the constraints/ prefix. Email-like names are
+// case-insensitive and are lowercased.
+func NormalizeResource(r string) string {
+	r = strings.TrimSpace(r)
+	r = strings.ReplaceAll(r, "\\", "/")
+	if rest, ok := strings.CutPrefix(r, "https://"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 && strings.HasSuffix(rest[:i], ".googleapis.com") {
+			rest = rest[i+1:]
+			if j := strings.Index(rest, "/"); j >= 0 && isAPIVersion(rest[:j]) {
+				rest = rest[j+1:]
+			}
+			r = rest
+		}
+	}
+	if rest, ok := strings.CutPrefix(r, "//"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 {
+			r = rest[i+1:]
+		}
+	}
+	r = strings.TrimPrefix(r, "./")
+	r = strings.TrimPrefix(r, "constraints/")
+	r = strings.TrimSuffix(r, "/")
+	if strings.Contains(r, "@") {
+		r = strings.ToLower(r)
+	}
+	return r
+}
+
+func isAPIVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' || s[1] < '0' || s[1] > '9' {
+		return false
+	}
+	return true
+}
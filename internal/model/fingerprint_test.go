@@ -0,0 +1,101 @@
+package model
+
+import "testing"
+
+func TestFingerprintGolden(t *testing.T) {
+	// These values are part of the baseline file format: changing them makes
+	// every existing baseline report all findings as new. Bump
+	// FingerprintVersion instead of editing them.
+	cases := []struct {
+		name    string
+		finding Finding
+		want    string
+	}{
+		{
+			name: "service account key",
+			finding: Finding{
+				ID:       "gcp.sa_key.no_expiry",
+				Project:  "demo",
+				Resource: "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123",
+				Metadata: map[string]string{
+					"service_account": "ci@demo.iam.gserviceaccount.com",
+					"key_name":        "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123",
+				},
+			},
+			want: "50a470c36c28ccbf0e2631ec7c6c624b",
+		},
+		{
+			name: "org policy",
+			finding: Finding{
+				ID:       "gcp.org_policy.compute.requireOsLogin",
+				Project:  "demo",
+				Resource: "demo",
+				Metadata: map[string]string{"constraint": "constraints/compute.requireOsLogin", "status": "not_set"},
+			},
+			want: "1e667dc19fc40d5da109356188a89452",
+		},
+		{
+			name: "local secret",
+			finding: Finding{
+				ID:       "local.secret.exposure",
+				Resource: "config/app.env",
+				Metadata: map[string]string{"pattern": "Google API Key", "secret_sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+			},
+			want: "e60adc76a7deda75e149c372f9068cd4",
+		},
+	}
+	for _, tc := range cases {
+		if got := Fingerprint(tc.finding); got != tc.want {
+			t.Errorf("%s: fingerprint = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFingerprintIgnoresVolatileFields(t *testing.T) {
+	base := Finding{
+		ID:          "gcp.sa_key.stale_review",
+		Check:       "Disable Dormant Keys",
+		Severity:    SeverityMedium,
+		Summary:     "Service account key is older than 30 days",
+		Description: "Key created 31 days ago.",
+		Project:     "demo",
+		Resource:    "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123",
+		Metadata:    map[string]string{"key_name": "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123", "created_at": "2020-01-01T00:00:00Z"},
+	}
+	want := Fingerprint(base)
+
+	changed := base
+	changed.Description = "Key created 400 days ago."
+	changed.Summary = "Service account key is older than 90 days"
+	changed.Severity = SeverityHigh
+	changed.Check = "Mandatory Rotation"
+	changed.Metadata = map[string]string{"key_name": base.Metadata["key_name"], "created_at": "2021-06-01T00:00:00Z"}
+	changed.BaselineState = BaselineNew
+	if got := Fingerprint(changed); got != want {
+		t.Fatalf("volatile fields changed the fingerprint: %s != %s", got, want)
+	}
+
+	for _, resource := range []string{
+		"//iam.googleapis.com/projects/demo/serviceAccounts/CI@demo.iam.gserviceaccount.com/keys/abc123",
+		"https://iam.googleapis.com/v1/projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123",
+		" projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc123/ ",
+	} {
+		alias := base
+		alias.Resource = resource
+		alias.Metadata = map[string]string{"key_name": resource}
+		if got := Fingerprint(alias); got != want {
+			t.Errorf("resource %q fingerprint = %s, want %s", resource, got, want)
+		}
+	}
+
+	other := base
+	other.Metadata = map[string]string{"key_name": "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/def456"}
+	if Fingerprint(other) == want {
+		t.Fatalf("different keys share a fingerprint")
+	}
+	other = base
+	other.Project = "prod"
+	if Fingerprint(other) == want {
+		t.Fatalf("different projects share a fingerprint")
+	}
+}
@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
 
 type Severity string
 
@@ -19,14 +24,146 @@ type Finding struct {
 	Summary        string            `json:"summary"`
 	Description    string            `json:"description"`
 	Resource       string            `json:"resource,omitempty"`
+	Project        string            `json:"project,omitempty"`
 	Recommendation string            `json:"recommendation"`
 	Metadata       map[string]string `json:"metadata,omitempty"`
+	Fingerprint    string            `json:"fingerprint,omitempty"`
+	BaselineState  string            `json:"baseline_state,omitempty"`
+	Sources        []Source          `json:"sources,omitempty"`
+}
+
+// Source identifies one scan that went into a merged result.
+type Source struct {
+	File         string    `json:"file,omitempty"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	Project      string    `json:"project,omitempty"`
+	Organization string    `json:"organization,omitempty"`
+	Folder       string    `json:"folder,omitempty"`
+	Repo         string    `json:"repo,omitempty"`
 }
 
+// Baseline states set on findings when a scan is compared to a baseline.
+const (
+	BaselineNew      = "new"
+	BaselineExisting = "existing"
+	BaselineFixed    = "fixed"
+)
+
 type ScanResult struct {
-	GeneratedAt time.Time `json:"generated_at"`
-	Project     string    `json:"project,omitempty"`
-	Repo        string    `json:"repo,omitempty"`
-	Findings    []Finding `json:"findings"`
-	Notes       []string  `json:"notes,omitempty"`
+	SchemaVersion int               `json:"schema_version"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Project       string            `json:"project,omitempty"`
+	Organization  string            `json:"organization,omitempty"`
+	Folder        string            `json:"folder,omitempty"`
+	Projects      []Project         `json:"projects,omitempty"`
+	Selection     *ProjectSelection `json:"selection,omitempty"`
+	Repo          string            `json:"repo,omitempty"`
+	Findings      []Finding         `json:"findings"`
+	Notes         []string          `json:"notes,omitempty"`
+	Errors        []ResourceError   `json:"errors,omitempty"`
+	Executions    []CheckExecution  `json:"executions,omitempty"`
+	Fixed         []Finding         `json:"fixed,omitempty"`
+	Sources       []Source          `json:"sources,omitempty"`
+	// Legacy is set on scans read from files written before schema_version
+	// existed. They carry no execution records, so gates cannot tell from
+	// them whether any check ran.
+	Legacy bool `json:"-"`
+}
+
+// ResourceError records a resource a check could not evaluate while the rest
+// of its findings were still reported.
+type ResourceError struct {
+	Check    string `json:"check"`
+	Project  string `json:"project,omitempty"`
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+}
+
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "passed"
+	CheckFailed  CheckStatus = "failed"
+	CheckErrored CheckStatus = "errored"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckExecution records how a single check run went. Passed and failed
+// mean the check completed without and with findings; errored means it
+// could not evaluate some or all resources.
+type CheckExecution struct {
+	Check              string      `json:"check"`
+	Project            string      `json:"project,omitempty"`
+	Target             string      `json:"target,omitempty"`
+	Status             CheckStatus `json:"status"`
+	Reason             string      `json:"reason"`
+	Message            string      `json:"message,omitempty"`
+	ErrorCategory      string      `json:"error_category,omitempty"`
+	DurationMillis     int64       `json:"duration_ms"`
+	ResourcesEvaluated int         `json:"resources_evaluated"`
+	Findings           int         `json:"findings"`
+	FindingIDs         []string    `json:"finding_ids,omitempty"`
+}
+
+// Evaluated reports whether the scan ran the check behind f to completion,
+// so that f being absent from it means it was fixed rather than not looked
+// at. The check is found through the finding ids each execution records; a
+// finding none of them claims was not looked at. Scans whose executions
+// record no finding ids only count when every check completed, and scans
+// without execution records are taken at their word.
+func (r ScanResult) Evaluated(f Finding) bool {
+	if len(r.Executions) == 0 {
+		return true
+	}
+	claimed, recorded := false, false
+	for _, e := range r.Executions {
+		recorded = recorded || len(e.FindingIDs) > 0
+		if slices.Contains(e.FindingIDs, f.ID) {
+			claimed = true
+			break
+		}
+	}
+	if recorded && !claimed {
+		return false
+	}
+	ran := false
+	for _, e := range r.Executions {
+		if e.Project != "" && f.Project != "" && e.Project != f.Project {
+			continue
+		}
+		if claimed && !slices.Contains(e.FindingIDs, f.ID) {
+			continue
+		}
+		if e.Status != CheckPassed && e.Status != CheckFailed {
+			return false
+		}
+		ran = true
+	}
+	return ran
+}
+
+// Rank orders severities from info (1) to critical (5); unknown values rank 0.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 5
+	case SeverityHigh:
+		return 4
+	case SeverityMedium:
+		return 3
+	case SeverityLow:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func ParseSeverity(v string) (Severity, error) {
+	s := Severity(strings.ToLower(strings.TrimSpace(v)))
+	if s.Rank() == 0 {
+		return "", fmt.Errorf("invalid severity: %s", v)
+	}
+	return s, nil
 }
@@ -0,0 +1,17 @@
+package model
+
+// OrgPolicyRule is one entry of the organization policy baseline: the
+// constraint to inspect and the state it is expected to be in. Empty
+// presentation fields are filled in by the scanner.
+type OrgPolicyRule struct {
+	Constraint     string   `json:"constraint"`
+	ID             string   `json:"id,omitempty"`
+	Check          string   `json:"check,omitempty"`
+	Severity       Severity `json:"severity,omitempty"`
+	Enforced       *bool    `json:"enforced,omitempty"`
+	AllowedValues  []string `json:"allowed_values,omitempty"`
+	DeniedValues   []string `json:"denied_values,omitempty"`
+	Summary        string   `json:"summary,omitempty"`
+	Recommendation string   `json:"recommendation,omitempty"`
+	Disabled       bool     `json:"disabled,omitempty"`
+}
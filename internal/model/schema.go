@@ -0,0 +1,227 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the scan file layout written by this
+// build. Bump it together with a migration in report.LoadScan whenever a
+// field is renamed, removed or changes meaning.
+const SchemaVersion = 1
+
+const SchemaID = "https://github.com/Andrei-Barwood/gcpsec/schema/scan.schema.json"
+
+// Schema is the subset of JSON Schema (draft 2020-12) needed to describe the
+// scan file.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(Severity("")): {
+		string(SeverityInfo), string(SeverityLow), string(SeverityMedium), string(SeverityHigh), string(SeverityCritical),
+	},
+	reflect.TypeOf(CheckStatus("")): {
+		string(CheckPassed), string(CheckFailed), string(CheckErrored), string(CheckSkipped),
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ScanSchema describes ScanResult as JSON Schema. Nested structs become
+// $defs entries named after their Go type; fields without omitempty are
+// required. Unknown properties are allowed so optional fields added without
+// a schema_version bump still validate; a newer schema_version is refused
+// when the scan is loaded.
+func ScanSchema() *Schema {
+	defs := map[string]*Schema{}
+	root := structSchema(reflect.TypeOf(ScanResult{}), defs)
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.ID = SchemaID
+	root.Title = "gcpsec scan result"
+	root.Defs = defs
+	return root
+}
+
+// ScanSchemaJSON is the published form of ScanSchema, as committed in
+// schema/scan.schema.json.
+func ScanSchemaJSON() ([]byte, error) {
+	buf, err := json.MarshalIndent(ScanSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
+
+func structSchema(t reflect.Type, defs map[string]*Schema) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = typeSchema(field.Type, defs)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+func typeSchema(t reflect.Type, defs map[string]*Schema) *Schema {
+	if enum, ok := schemaEnums[t]; ok {
+		return &Schema{Type: "string", Enum: enum}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), defs)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	}
+	panic(fmt.Sprintf("model: no JSON Schema mapping for %s", t))
+}
+
+// Validate checks a decoded JSON value against s and returns one message per
+// violation, each prefixed with the path of the offending value.
+func (s *Schema) Validate(v any) []string {
+	var problems []string
+	s.validate(s, "$", v, &problems)
+	return problems
+}
+
+func (s *Schema) validate(root *Schema, path string, v any, problems *[]string) {
+	if s.Ref != "" {
+		def := root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if def == nil {
+			*problems = append(*problems, fmt.Sprintf("%s: unresolved reference %s", path, s.Ref))
+			return
+		}
+		def.validate(root, path, v, problems)
+		return
+	}
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("expected object, got %s", jsonType(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(root, path+"."+k, obj[k], problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(root, path+"."+k, obj[k], problems)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail("expected array, got %s", jsonType(v))
+			return
+		}
+		for i, item := range arr {
+			s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item, problems)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("expected string, got %s", jsonType(v))
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				fail("%q is not an RFC 3339 date-time", str)
+			}
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			fail("expected integer, got %s", jsonType(v))
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			fail("expected number, got %s", jsonType(v))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("expected boolean, got %s", jsonType(v))
+		}
+	}
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
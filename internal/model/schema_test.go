@@ -0,0 +1,63 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommittedSchemaMatchesModel(t *testing.T) {
+	want, err := ScanSchemaJSON()
+	if err != nil {
+		t.Fatalf("generate schema: %v", err)
+	}
+	got, err := os.ReadFile("../../schema/scan.schema.json")
+	if err != nil {
+		t.Fatalf("read committed schema: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("schema/scan.schema.json is out of date with the model; run make schema")
+	}
+}
+
+func TestScanSchemaValidatesScans(t *testing.T) {
+	result := ScanResult{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Findings: []Finding{{
+			ID:       "gcp.api_key.unrestricted",
+			Check:    "API Key Restrictions",
+			Severity: SeverityHigh,
+			Metadata: map[string]string{"key_name": "maps"},
+		}},
+		Executions: []CheckExecution{{Check: "api-keys", Status: CheckFailed, Reason: "findings_reported"}},
+	}
+	buf, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if problems := ScanSchema().Validate(raw); len(problems) != 0 {
+		t.Fatalf("expected a valid scan, got %v", problems)
+	}
+
+	finding := raw["findings"].([]any)[0].(map[string]any)
+	finding["severity"] = "urgent"
+	delete(finding, "check")
+	finding["metadata"].(map[string]any)["key_name"] = 7.0
+	problems := ScanSchema().Validate(raw)
+	want := []string{
+		`$.findings[0]: missing required property "check"`,
+		"$.findings[0].metadata.key_name: expected string, got number",
+		`$.findings[0].severity: "urgent" is not one of info, low, medium, high, critical`,
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(problems, "\n"))
+	}
+}
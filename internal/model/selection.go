@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+type Project struct {
+	ID     string            `json:"id"`
+	Number string            `json:"number,omitempty"`
+	State  string            `json:"state,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type ProjectSelection struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	States  []string `json:"states,omitempty"`
+}
+
+func (s ProjectSelection) IsZero() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0 && len(s.Labels) == 0 && len(s.States) == 0
+}
+
+func (s ProjectSelection) Validate() error {
+	for _, pattern := range append(append([]string(nil), s.Include...), s.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid project pattern %q: %w", pattern, err)
+		}
+	}
+	for _, expr := range s.Labels {
+		if _, _, _, err := parseLabelExpr(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s ProjectSelection) Match(p Project) bool {
+	if len(s.States) > 0 && !s.matchState(p.State) {
+		return false
+	}
+	if len(s.Include) > 0 && !matchAny(s.Include, p.ID) {
+		return false
+	}
+	if matchAny(s.Exclude, p.ID) {
+		return false
+	}
+	for _, expr := range s.Labels {
+		key, op, value, err := parseLabelExpr(expr)
+		if err != nil {
+			return false
+		}
+		got, ok := p.Labels[key]
+		switch op {
+		case "=":
+			if !ok || got != value {
+				return false
+			}
+		case "!=":
+			if ok && got == value {
+				return false
+			}
+		case "exists":
+			if !ok {
+				return false
+			}
+		case "!exists":
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s ProjectSelection) matchState(state string) bool {
+	for _, want := range s.States {
+		if strings.EqualFold(want, "any") || strings.EqualFold(want, state) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func parseLabelExpr(expr string) (string, string, string, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case strings.Contains(expr, "!="):
+		parts := strings.SplitN(expr, "!=", 2)
+		return validLabel(expr, strings.TrimSpace(parts[0]), "!=", strings.TrimSpace(parts[1]))
+	case strings.Contains(expr, "="):
+		parts := strings.SplitN(expr, "=", 2)
+		return validLabel(expr, strings.TrimSpace(parts[0]), "=", strings.TrimSpace(parts[1]))
+	case strings.HasPrefix(expr, "!"):
+		return validLabel(expr, strings.TrimSpace(expr[1:]), "!exists", "")
+	default:
+		return validLabel(expr, expr, "exists", "")
+	}
+}
+
+func validLabel(expr, key, op, value string) (string, string, string, error) {
+	if key == "" {
+		return "", "", "", fmt.Errorf("invalid label selector %q", expr)
+	}
+	return key, op, value, nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import "testing"
+
+func TestProjectSelectionMatch(t *testing.T) {
+	sel := ProjectSelection{
+		Include: []string{"app-*"},
+		Exclude: []string{"sys-*", "app-sandbox-*"},
+		Labels:  []string{"env=prod", "!legacy"},
+		States:  []string{"ACTIVE"},
+	}
+	if err := sel.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		project Project
+		want    bool
+	}{
+		{"matches", Project{ID: "app-api", State: "ACTIVE", Labels: map[string]string{"env": "prod"}}, true},
+		{"wrong env", Project{ID: "app-api", State: "ACTIVE", Labels: map[string]string{"env": "dev"}}, false},
+		{"excluded", Project{ID: "app-sandbox-1", State: "ACTIVE", Labels: map[string]string{"env": "prod"}}, false},
+		{"not included", Project{ID: "data-lake", State: "ACTIVE", Labels: map[string]string{"env": "prod"}}, false},
+		{"legacy label", Project{ID: "app-old", State: "ACTIVE", Labels: map[string]string{"env": "prod", "legacy": "true"}}, false},
+		{"deleted", Project{ID: "app-api", State: "DELETE_REQUESTED", Labels: map[string]string{"env": "prod"}}, false},
+	}
+	for _, tc := range cases {
+		if got := sel.Match(tc.project); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
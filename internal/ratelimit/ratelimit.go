@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket shared by every goroutine issuing API calls. A
+// nil *Limiter never blocks.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func New(perSecond float64, burst int) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: perSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type Backoff struct {
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+}
+
+func DefaultBackoff() Backoff {
+	return Backoff{Attempts: 5, Base: time.Second, Max: 30 * time.Second}
+}
+
+// Retry calls fn until it succeeds, returns an error retryable rejects, or
+// the attempts are exhausted. Delays grow exponentially with jitter.
+func Retry(ctx context.Context, b Backoff, retryable func(error) bool, fn func() error) error {
+	if b.Attempts < 1 {
+		b.Attempts = 1
+	}
+	var err error
+	for attempt := 0; attempt < b.Attempts; attempt++ {
+		if err = fn(); err == nil || !retryable(err) {
+			return err
+		}
+		if attempt == b.Attempts-1 {
+			break
+		}
+
+		delay := b.Base << attempt
+		if b.Max > 0 && (delay > b.Max || delay <= 0) {
+			delay = b.Max
+		}
+		if delay > 0 {
+			delay = time.Duration(rand.Int63n(int64(delay))) + delay/2
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
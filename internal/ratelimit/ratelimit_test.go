@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesCallsAfterBurst(t *testing.T) {
+	l := New(100, 2)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected 4 calls beyond the burst to take ~40ms, took %s", elapsed)
+	}
+}
+
+func TestLimiterHonoursContext(t *testing.T) {
+	l := New(0.001, 1)
+	_ = l.Wait(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRetryStopsOnNonRetryableError(t *testing.T) {
+	quota := errors.New("RESOURCE_EXHAUSTED")
+	calls := 0
+	err := Retry(context.Background(), Backoff{Attempts: 5, Base: time.Millisecond}, func(err error) bool { return errors.Is(err, quota) }, func() error {
+		calls++
+		if calls < 3 {
+			return quota
+		}
+		return errors.New("PERMISSION_DENIED")
+	})
+	if calls != 3 || err == nil || err.Error() != "PERMISSION_DENIED" {
+		t.Fatalf("expected to stop after the non-retryable error, calls=%d err=%v", calls, err)
+	}
+}
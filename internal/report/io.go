@@ -19,14 +19,111 @@ func Save(path string, data []byte) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
+// LoadScan reads a scan file and migrates it to the current schema version.
 func LoadScan(path string) (model.ScanResult, error) {
 	buf, err := os.ReadFile(path)
 	if err != nil {
 		return model.ScanResult{}, err
 	}
+	return DecodeScan(buf)
+}
+
+func DecodeScan(buf []byte) (model.ScanResult, error) {
+	raw, from, err := migrate(buf)
+	if err != nil {
+		return model.ScanResult{}, err
+	}
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return model.ScanResult{}, err
+	}
 	var scan model.ScanResult
-	if err := json.Unmarshal(buf, &scan); err != nil {
+	if err := json.Unmarshal(migrated, &scan); err != nil {
 		return model.ScanResult{}, err
 	}
+	scan.Legacy = from == 0
 	return scan, nil
 }
+
+// Validate checks a scan file against the published JSON Schema after
+// migrating it. It returns the version the file was written with and one
+// message per schema violation; the error is set only when the file is not a
+// scan gcpsec can read at all.
+func Validate(buf []byte) (int, []string, error) {
+	raw, from, err := migrate(buf)
+	if err != nil {
+		return from, nil, err
+	}
+	return from, model.ScanSchema().Validate(raw), nil
+}
+
+// migrations[v] upgrades a decoded scan from schema version v to v+1.
+var migrations = []func(map[string]any) error{
+	migrateV0,
+}
+
+func migrate(buf []byte) (map[string]any, int, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, 0, err
+	}
+	version := 0
+	if v, ok := raw["schema_version"]; ok {
+		n, ok := v.(float64)
+		if !ok || n != float64(int(n)) || n < 0 {
+			return nil, 0, fmt.Errorf("invalid schema_version: %v", v)
+		}
+		version = int(n)
+	}
+	if version > model.SchemaVersion {
+		return nil, version, fmt.Errorf("scan uses schema version %d; this gcpsec reads up to %d", version, model.SchemaVersion)
+	}
+	for v := version; v < model.SchemaVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, version, fmt.Errorf("migrate scan from schema version %d: %w", v, err)
+		}
+	}
+	raw["schema_version"] = float64(model.SchemaVersion)
+	return raw, version, nil
+}
+
+// migrateV0 upgrades scans written before schema_version existed: projects
+// may be a list of plain ids, and findings may be null and carry no
+// fingerprint.
+func migrateV0(raw map[string]any) error {
+	if projects, ok := raw["projects"].([]any); ok {
+		for i, p := range projects {
+			if id, ok := p.(string); ok {
+				projects[i] = map[string]any{"id": id}
+			}
+		}
+	}
+
+	findings, ok := raw["findings"].([]any)
+	if !ok {
+		if raw["findings"] == nil {
+			raw["findings"] = []any{}
+		}
+		return nil
+	}
+	for _, item := range findings {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if fp, _ := obj["fingerprint"].(string); fp != "" {
+			continue
+		}
+		buf, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		var f model.Finding
+		if err := json.Unmarshal(buf, &f); err != nil {
+			// Malformed findings are left for schema validation to report.
+			continue
+		}
+		obj["fingerprint"] = model.Fingerprint(f)
+	}
+	return nil
+}
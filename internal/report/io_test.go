@@ -0,0 +1,59 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestLoadScanMigratesUnversionedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	legacy := `{"generated_at":"2026-03-01T00:00:00Z","project":"demo","findings":[{"id":"gcp.api_key.unrestricted","check":"API Key Restrictions","severity":"high","summary":"s","description":"d","resource":"maps","project":"demo","recommendation":"r"}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	scan, err := LoadScan(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if scan.SchemaVersion != model.SchemaVersion {
+		t.Fatalf("expected schema version %d, got %d", model.SchemaVersion, scan.SchemaVersion)
+	}
+	if !scan.Legacy {
+		t.Fatal("expected the unversioned scan to be marked legacy")
+	}
+	if len(scan.Findings) != 1 || scan.Findings[0].Fingerprint != model.Fingerprint(scan.Findings[0]) {
+		t.Fatalf("expected the finding to gain its fingerprint, got %+v", scan.Findings)
+	}
+
+	version, problems, err := Validate([]byte(legacy))
+	if err != nil || version != 0 || len(problems) != 0 {
+		t.Fatalf("expected the legacy scan to validate after migration, got v%d %v (%v)", version, problems, err)
+	}
+}
+
+func TestLoadScanMigratesProjectIDList(t *testing.T) {
+	legacy := []byte(`{"generated_at":"2026-03-01T00:00:00Z","organization":"123","projects":["app-prod","app-dev"],"findings":[]}`)
+
+	scan, err := DecodeScan(legacy)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(scan.Projects) != 2 || scan.Projects[0].ID != "app-prod" || scan.Projects[1].ID != "app-dev" {
+		t.Fatalf("expected project ids to become project objects, got %+v", scan.Projects)
+	}
+	if _, problems, err := Validate(legacy); err != nil || len(problems) != 0 {
+		t.Fatalf("expected the legacy organization scan to validate, got %v (%v)", problems, err)
+	}
+}
+
+func TestLoadScanRejectsNewerSchema(t *testing.T) {
+	_, err := DecodeScan([]byte(`{"schema_version":99,"generated_at":"2026-03-01T00:00:00Z","findings":[]}`))
+	if err == nil || !strings.Contains(err.Error(), "schema version 99") {
+		t.Fatalf("expected a newer schema version to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+// LoadScans reads one or more scan files. A single file is returned as is;
+// several are merged, recording each path as the source of its findings.
+func LoadScans(paths []string) (model.ScanResult, error) {
+	if len(paths) == 1 {
+		return LoadScan(paths[0])
+	}
+	scans := make([]model.ScanResult, 0, len(paths))
+	for _, path := range paths {
+		scan, err := LoadScan(path)
+		if err != nil {
+			return model.ScanResult{}, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(scan.Sources) == 0 {
+			src := SourceOf(scan)
+			src.File = path
+			scan.Sources = []model.Source{src}
+		}
+		scans = append(scans, scan)
+	}
+	return Merge(scans...), nil
+}
+
+func SourceOf(scan model.ScanResult) model.Source {
+	return model.Source{
+		GeneratedAt:  scan.GeneratedAt,
+		Project:      scan.Project,
+		Organization: scan.Organization,
+		Folder:       scan.Folder,
+		Repo:         scan.Repo,
+	}
+}
+
+// Merge combines scans into one result. Findings are deduplicated by
+// fingerprint and list every scan that reported them in Sources; a scan that
+// is itself a merge contributes its own sources. Notes, resource errors and
+// project inventories are unioned, and execution records are kept once per
+// check and target, preferring the most recent scan. Baseline state is
+// dropped: apply --baseline to the merged result instead.
+func Merge(scans ...model.ScanResult) model.ScanResult {
+	merged := model.ScanResult{SchemaVersion: model.SchemaVersion, Findings: []model.Finding{}, Legacy: len(scans) > 0}
+
+	findingAt := map[string]int{}
+	projectSeen := map[string]bool{}
+	noteSeen := map[string]bool{}
+	errorSeen := map[model.ResourceError]bool{}
+	type execKey struct{ check, project, target string }
+	execAt := map[execKey]int{}
+	execTime := map[execKey]int64{}
+
+	for _, scan := range scans {
+		sources := scan.Sources
+		if len(sources) == 0 {
+			sources = []model.Source{SourceOf(scan)}
+		}
+		merged.Sources = appendSources(merged.Sources, sources...)
+		merged.Legacy = merged.Legacy && scan.Legacy
+		if scan.GeneratedAt.After(merged.GeneratedAt) {
+			merged.GeneratedAt = scan.GeneratedAt
+		}
+
+		for _, f := range scan.Findings {
+			fp := f.Fingerprint
+			if fp == "" {
+				fp = model.Fingerprint(f)
+			}
+			from := f.Sources
+			if len(from) == 0 {
+				from = sources
+			}
+			if i, ok := findingAt[fp]; ok {
+				merged.Findings[i].Sources = appendSources(merged.Findings[i].Sources, from...)
+				continue
+			}
+			f.Fingerprint = fp
+			f.BaselineState = ""
+			f.Sources = appendSources(nil, from...)
+			findingAt[fp] = len(merged.Findings)
+			merged.Findings = append(merged.Findings, f)
+		}
+
+		for _, p := range scan.Projects {
+			if !projectSeen[p.ID] {
+				projectSeen[p.ID] = true
+				merged.Projects = append(merged.Projects, p)
+			}
+		}
+		for _, note := range scan.Notes {
+			if !noteSeen[note] {
+				noteSeen[note] = true
+				merged.Notes = append(merged.Notes, note)
+			}
+		}
+		for _, e := range scan.Errors {
+			if !errorSeen[e] {
+				errorSeen[e] = true
+				merged.Errors = append(merged.Errors, e)
+			}
+		}
+		for _, e := range scan.Executions {
+			key := execKey{e.Check, e.Project, e.Target}
+			at := scan.GeneratedAt.UnixNano()
+			if i, ok := execAt[key]; ok {
+				if at > execTime[key] {
+					merged.Executions[i] = e
+					execTime[key] = at
+				}
+				continue
+			}
+			execAt[key] = len(merged.Executions)
+			execTime[key] = at
+			merged.Executions = append(merged.Executions, e)
+		}
+	}
+
+	merged.Project = common(scans, func(s model.ScanResult) string { return s.Project })
+	merged.Organization = common(scans, func(s model.ScanResult) string { return s.Organization })
+	merged.Folder = common(scans, func(s model.ScanResult) string { return s.Folder })
+	merged.Repo = common(scans, func(s model.ScanResult) string { return s.Repo })
+	for _, scan := range scans {
+		if scan.Selection == nil {
+			continue
+		}
+		if merged.Selection != nil && !reflect.DeepEqual(merged.Selection, scan.Selection) {
+			merged.Selection = nil
+			break
+		}
+		merged.Selection = scan.Selection
+	}
+	return merged
+}
+
+func appendSources(list []model.Source, sources ...model.Source) []model.Source {
+	for _, src := range sources {
+		dup := false
+		for _, have := range list {
+			if have == src {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, src)
+		}
+	}
+	return list
+}
+
+// common returns the value every scan that sets it agrees on, or "" when
+// they disagree.
+func common(scans []model.ScanResult, field func(model.ScanResult) string) string {
+	var value string
+	for _, scan := range scans {
+		v := field(scan)
+		if v == "" {
+			continue
+		}
+		if value != "" && value != v {
+			return ""
+		}
+		value = v
+	}
+	return value
+}
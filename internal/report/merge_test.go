@@ -0,0 +1,78 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestMergeDeduplicatesByFingerprintAndKeepsProvenance(t *testing.T) {
+	early := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+	secret := model.Finding{ID: "local.secret.exposure", Check: "Zero-Code Storage", Severity: model.SeverityHigh, Resource: "main.tf", Metadata: map[string]string{"secret_sha256": "abc"}}
+	key := model.Finding{ID: "gcp.api_key.unrestricted", Check: "API Key Restrictions", Severity: model.SeverityHigh, Resource: "maps", Project: "demo"}
+
+	repoScan := model.ScanResult{
+		GeneratedAt: early,
+		Repo:        ".",
+		Findings:    []model.Finding{secret},
+		Notes:       []string{"project not set; skipping gcloud-based checks"},
+		Executions:  []model.CheckExecution{{Check: "local-secrets", Target: ".", Status: model.CheckFailed}},
+	}
+	projectScan := model.ScanResult{
+		GeneratedAt: late,
+		Project:     "demo",
+		Repo:        ".",
+		Findings:    []model.Finding{key, secret},
+		Executions: []model.CheckExecution{
+			{Check: "local-secrets", Target: ".", Status: model.CheckPassed},
+			{Check: "api-keys", Project: "demo", Target: "projects/demo", Status: model.CheckFailed},
+		},
+	}
+
+	dir := t.TempDir()
+	var paths []string
+	for i, scan := range []model.ScanResult{repoScan, projectScan} {
+		payload, err := json.Marshal(scan)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		path := filepath.Join(dir, []string{"repo.json", "project.json"}[i])
+		if err := os.WriteFile(path, payload, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	merged, err := LoadScans(paths)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(merged.Findings) != 2 {
+		t.Fatalf("expected the shared secret once, got %+v", merged.Findings)
+	}
+	if got := merged.Findings[0].Sources; len(got) != 2 || got[0].File != paths[0] || got[1].Project != "demo" {
+		t.Fatalf("expected the secret to list both scans, got %+v", got)
+	}
+	if got := merged.Findings[1].Sources; len(got) != 1 || got[0].File != paths[1] {
+		t.Fatalf("expected the API key to come from the project scan, got %+v", got)
+	}
+	if merged.Project != "demo" || merged.Repo != "." || !merged.GeneratedAt.Equal(late) || len(merged.Sources) != 2 {
+		t.Fatalf("unexpected merged header: %+v", merged)
+	}
+	if len(merged.Notes) != 1 {
+		t.Fatalf("expected notes to be kept, got %v", merged.Notes)
+	}
+	if len(merged.Executions) != 2 || merged.Executions[0].Status != model.CheckPassed {
+		t.Fatalf("expected the latest local-secrets execution to win, got %+v", merged.Executions)
+	}
+
+	again := Merge(merged, repoScan)
+	if len(again.Findings) != 2 || len(again.Findings[0].Sources) != 3 {
+		t.Fatalf("expected merging a merged scan to keep its sources, got %+v", again.Findings[0].Sources)
+	}
+}
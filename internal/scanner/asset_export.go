@@ -0,0 +1,391 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type assetRecord struct {
+	Name           string           `json:"name"`
+	AssetType      string           `json:"asset_type"`
+	AssetTypeCamel string           `json:"assetType"`
+	Ancestors      []string         `json:"ancestors"`
+	IAMPolicy      map[string]any   `json:"iam_policy"`
+	IAMPolicyCamel map[string]any   `json:"iamPolicy"`
+	OrgPolicy      []map[string]any `json:"org_policy"`
+	OrgPolicyCamel []map[string]any `json:"orgPolicy"`
+	Resource       struct {
+		Data map[string]any `json:"data"`
+	} `json:"resource"`
+}
+
+type assetSource struct {
+	assets      int
+	projects    map[string]model.Project
+	idToNumber  map[string]string
+	ancestry    map[string][]string
+	apiKeyList  map[string][]map[string]any
+	accountList map[string][]map[string]any
+	keyList     map[string][]map[string]any
+	orgPolicies map[string]map[string]gcpapi.OrgPolicy
+	iamPolicies map[string]map[string]any
+	orgs        map[string]bool
+}
+
+func loadAssetExport(path string) (*assetSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	src := &assetSource{
+		projects:    map[string]model.Project{},
+		idToNumber:  map[string]string{},
+		ancestry:    map[string][]string{},
+		apiKeyList:  map[string][]map[string]any{},
+		accountList: map[string][]map[string]any{},
+		keyList:     map[string][]map[string]any{},
+		orgPolicies: map[string]map[string]gcpapi.OrgPolicy{},
+		iamPolicies: map[string]map[string]any{},
+		orgs:        map[string]bool{},
+	}
+
+	lines := bufio.NewScanner(f)
+	lines.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for lines.Scan() {
+		lineNo++
+		raw := strings.TrimSpace(lines.Text())
+		if raw == "" {
+			continue
+		}
+		var rec assetRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid asset record: %w", path, lineNo, err)
+		}
+		src.add(rec)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func (a *assetSource) add(rec assetRecord) {
+	a.assets++
+	assetType := rec.AssetType
+	if assetType == "" {
+		assetType = rec.AssetTypeCamel
+	}
+	data := rec.Resource.Data
+	owner := ""
+	if len(rec.Ancestors) > 0 {
+		owner = rec.Ancestors[0]
+	}
+	for _, res := range append([]string{crmResourceName(rec.Name)}, rec.Ancestors...) {
+		if id, ok := strings.CutPrefix(res, "organizations/"); ok {
+			a.orgs[id] = true
+		}
+	}
+
+	if name := crmResourceName(rec.Name); name != "" {
+		if len(rec.Ancestors) > 0 {
+			a.ancestry[name] = rec.Ancestors
+		}
+		if policy := firstMap(rec.IAMPolicy, rec.IAMPolicyCamel); policy != nil {
+			a.iamPolicies[name] = policy
+		}
+		for _, p := range append(rec.OrgPolicy, rec.OrgPolicyCamel...) {
+			if a.orgPolicies[name] == nil {
+				a.orgPolicies[name] = map[string]gcpapi.OrgPolicy{}
+			}
+			constraint := normalizeConstraint(asString(p["constraint"]))
+			a.orgPolicies[name][constraint] = v1OrgPolicyToV2(p)
+		}
+	}
+
+	if data == nil {
+		return
+	}
+
+	switch assetType {
+	case "cloudresourcemanager.googleapis.com/Project":
+		number := asString(data["projectNumber"])
+		if number == "" {
+			number = strings.TrimPrefix(crmResourceName(rec.Name), "projects/")
+		}
+		p := model.Project{
+			ID:     asString(data["projectId"]),
+			Number: number,
+			State:  asString(data["lifecycleState"]),
+			Labels: stringMap(data["labels"]),
+		}
+		a.projects[number] = p
+		a.idToNumber[p.ID] = number
+	case "apikeys.googleapis.com/Key":
+		a.apiKeyList[owner] = append(a.apiKeyList[owner], data)
+	case "iam.googleapis.com/ServiceAccount":
+		a.accountList[owner] = append(a.accountList[owner], data)
+	case "iam.googleapis.com/ServiceAccountKey":
+		if kt := asString(data["keyType"]); kt != "" && kt != "USER_MANAGED" {
+			return
+		}
+		account := between(rec.Name, "/serviceAccounts/", "/keys/")
+		if asString(data["name"]) == "" {
+			data["name"] = strings.TrimPrefix(rec.Name, "//iam.googleapis.com/")
+		}
+		a.keyList[account] = append(a.keyList[account], data)
+	}
+}
+
+func (a *assetSource) projectName(project string) string {
+	if number, ok := a.idToNumber[project]; ok {
+		return "projects/" + number
+	}
+	return "projects/" + project
+}
+
+func (a *assetSource) refName(ref resourceRef) string {
+	if ref.kind == "project" {
+		return a.projectName(ref.id)
+	}
+	return ref.name()
+}
+
+func (a *assetSource) apiKeys(_ context.Context, project string) ([]gcpapi.APIKey, error) {
+	var keys []gcpapi.APIKey
+	err := gcpapi.DecodeValue(a.apiKeyList[a.projectName(project)], &keys)
+	return keys, err
+}
+
+func (a *assetSource) serviceAccounts(_ context.Context, project string) ([]gcpapi.ServiceAccount, error) {
+	var accounts []gcpapi.ServiceAccount
+	err := gcpapi.DecodeValue(a.accountList[a.projectName(project)], &accounts)
+	return accounts, err
+}
+
+func (a *assetSource) serviceAccountKeys(ctx context.Context, project, email string) ([]gcpapi.ServiceAccountKey, error) {
+	raw := append([]map[string]any(nil), a.keyList[email]...)
+	accounts, err := a.serviceAccounts(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	for _, acct := range accounts {
+		if acct.Email == email && acct.UniqueID != "" && acct.UniqueID != email {
+			raw = append(raw, a.keyList[acct.UniqueID]...)
+		}
+	}
+	var keys []gcpapi.ServiceAccountKey
+	err = gcpapi.DecodeValue(raw, &keys)
+	return keys, err
+}
+
+func (a *assetSource) orgPolicy(_ context.Context, constraint string, ref resourceRef, effective bool) (gcpapi.OrgPolicy, error) {
+	name := a.refName(ref)
+	constraint = normalizeConstraint(constraint)
+	if !effective {
+		return a.orgPolicies[name][constraint], nil
+	}
+
+	chain := a.ancestry[name]
+	if len(chain) == 0 {
+		chain = []string{name}
+	}
+	for _, res := range chain {
+		if p := a.orgPolicies[res][constraint]; p.IsSet() {
+			return p, nil
+		}
+	}
+	return gcpapi.OrgPolicy{}, nil
+}
+
+func (a *assetSource) ancestors(_ context.Context, project string) ([]resourceRef, error) {
+	var out []resourceRef
+	for _, res := range a.ancestry[a.projectName(project)] {
+		kind, id, _ := strings.Cut(res, "/")
+		switch kind {
+		case "projects":
+			if p, ok := a.projects[id]; ok && p.ID != "" {
+				id = p.ID
+			}
+			out = append(out, resourceRef{kind: "project", id: id})
+		case "folders":
+			out = append(out, resourceRef{kind: "folder", id: id})
+		case "organizations":
+			out = append(out, resourceRef{kind: "organization", id: id})
+		}
+	}
+	return out, nil
+}
+
+func (a *assetSource) iamPolicy(_ context.Context, ref resourceRef) (map[string]any, error) {
+	if p, ok := a.iamPolicies[a.refName(ref)]; ok {
+		return p, nil
+	}
+	return map[string]any{}, nil
+}
+
+func (a *assetSource) contacts(context.Context, string, string) ([]gcpapi.Contact, error) {
+	return nil, skipCheck(reasonNotInExport, "Essential Contacts are not included in asset exports")
+}
+
+func (a *assetSource) childProjects(_ context.Context, parent resourceRef) ([]model.Project, error) {
+	var out []model.Project
+	for _, p := range a.projectList() {
+		if chain := a.ancestry["projects/"+p.Number]; len(chain) > 1 && chain[1] == parent.name() {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (a *assetSource) childFolders(_ context.Context, parent resourceRef) ([]resourceRef, error) {
+	seen := map[string]bool{}
+	var out []resourceRef
+	for _, chain := range a.ancestry {
+		for i := 1; i < len(chain); i++ {
+			id, ok := strings.CutPrefix(chain[i-1], "folders/")
+			if ok && chain[i] == parent.name() && !seen[id] {
+				seen[id] = true
+				out = append(out, resourceRef{kind: "folder", id: id})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out, nil
+}
+
+func (a *assetSource) projectList() []model.Project {
+	out := make([]model.Project, 0, len(a.projects))
+	for _, p := range a.projects {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// organizations lists every organization the export mentions, whether as
+// an asset of its own (IAM or org policy records) or as an ancestor.
+func (a *assetSource) organizations() []resourceRef {
+	var out []resourceRef
+	for id := range a.orgs {
+		out = append(out, resourceRef{kind: "organization", id: id})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out
+}
+
+func (s *Scanner) scanAssetExport(ctx context.Context, result *model.ScanResult) error {
+	src, err := loadAssetExport(s.opts.AssetExport)
+	if err != nil {
+		return err
+	}
+	s.src = src
+	result.Notes = append(result.Notes, fmt.Sprintf("offline scan from asset export %s (%d assets)", s.opts.AssetExport, src.assets))
+
+	for _, org := range src.organizations() {
+		root := s.forRoot(org)
+		root.runChecks(ctx, result, root.checksFor(ScopeOrganization))
+	}
+
+	selection := s.opts.Selection
+	if len(selection.States) == 0 {
+		selection.States = []string{"ACTIVE"}
+	}
+	if !selection.IsZero() {
+		result.Selection = &selection
+	}
+
+	var projects []model.Project
+	for _, p := range src.projectList() {
+		if s.opts.Project != "" && p.ID != s.opts.Project {
+			continue
+		}
+		if selection.Match(p) {
+			projects = append(projects, p)
+		}
+	}
+	if s.opts.Project != "" && len(projects) == 0 {
+		result.Notes = append(result.Notes, fmt.Sprintf("project %s not found in asset export", s.opts.Project))
+	}
+
+	s.scanProjects(ctx, result, projects)
+	return nil
+}
+
+func v1OrgPolicyToV2(p map[string]any) gcpapi.OrgPolicy {
+	spec := &gcpapi.PolicySpec{}
+	if bp := asMap(pick(p, "boolean_policy", "booleanPolicy")); bp != nil {
+		spec.Rules = []gcpapi.PolicyRule{{Enforce: asBool(bp["enforced"])}}
+	}
+	if lp := asMap(pick(p, "list_policy", "listPolicy")); lp != nil {
+		var rule gcpapi.PolicyRule
+		switch asString(pick(lp, "all_values", "allValues")) {
+		case "ALLOW":
+			rule.AllowAll = true
+		case "DENY":
+			rule.DenyAll = true
+		}
+		allowed := stringSlice(pick(lp, "allowed_values", "allowedValues"))
+		denied := stringSlice(pick(lp, "denied_values", "deniedValues"))
+		if len(allowed) > 0 || len(denied) > 0 {
+			rule.Values = &gcpapi.PolicyValues{AllowedValues: allowed, DeniedValues: denied}
+		}
+		if rule.AllowAll || rule.DenyAll || rule.Values != nil {
+			spec.Rules = []gcpapi.PolicyRule{rule}
+		}
+	}
+	if pick(p, "restore_default", "restoreDefault") != nil {
+		spec.Reset = true
+	}
+	return gcpapi.OrgPolicy{Name: asString(p["constraint"]), Spec: spec}
+}
+
+func crmResourceName(assetName string) string {
+	name := strings.TrimPrefix(assetName, "//cloudresourcemanager.googleapis.com/")
+	if name == assetName {
+		return ""
+	}
+	for _, prefix := range []string{"projects/", "folders/", "organizations/"} {
+		if strings.HasPrefix(name, prefix) {
+			return name
+		}
+	}
+	return ""
+}
+
+func between(s, start, end string) string {
+	_, rest, ok := strings.Cut(s, start)
+	if !ok {
+		return ""
+	}
+	out, _, _ := strings.Cut(rest, end)
+	return out
+}
+
+func pick(m map[string]any, keys ...string) any {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func firstMap(maps ...map[string]any) map[string]any {
+	for _, m := range maps {
+		if m != nil {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestScanAssetExportBuildsFindingsOffline(t *testing.T) {
+	baseline := []model.OrgPolicyRule{
+		withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/compute.requireOsLogin", Enforced: boolPtr(true)}),
+		withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/iam.disableServiceAccountKeyUpload", Enforced: boolPtr(true)}),
+	}
+	s := New(Options{
+		AssetExport:       "testdata/asset_export.jsonl",
+		Runner:            stubRunner{},
+		OrgPolicyBaseline: baseline,
+	})
+
+	result, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	for _, note := range result.Notes {
+		if strings.Contains(note, "failed") {
+			t.Fatalf("unexpected failure note: %s", note)
+		}
+	}
+
+	got := map[string]int{}
+	for _, f := range result.Findings {
+		got[f.ID]++
+		if f.ID == "gcp.org_policy.iam.disableServiceAccountKeyUpload" && f.Project != "" && f.Metadata["policy_source"] != "projects/app-prod" {
+			t.Fatalf("expected project-level source, got %q", f.Metadata["policy_source"])
+		}
+	}
+
+	want := map[string]int{
+		"gcp.iam.public_member":                             1,
+		"gcp.api_key.unrestricted":                          1,
+		"gcp.sa_key.no_expiry":                              1,
+		"gcp.sa_key.stale_review":                           1,
+		"gcp.org_policy.iam.disableServiceAccountKeyUpload": 2,
+	}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("expected %d %s findings, got %d", n, id, got[id])
+		}
+	}
+	if got["gcp.org_policy.compute.requireOsLogin"] != 0 {
+		t.Errorf("expected org-level enforcement to be inherited")
+	}
+	if len(result.Projects) != 1 || result.Projects[0].ID != "app-prod" {
+		t.Fatalf("expected app-prod in projects, got %+v", result.Projects)
+	}
+}
+
+func TestAssetExportFindsOrganizationsWithoutIAMRecords(t *testing.T) {
+	export := `{"name":"//cloudresourcemanager.googleapis.com/organizations/42","asset_type":"cloudresourcemanager.googleapis.com/Organization","ancestors":["organizations/42"],"org_policy":[{"constraint":"constraints/compute.requireOsLogin","boolean_policy":{"enforced":true}}]}
+{"name":"//cloudresourcemanager.googleapis.com/projects/111","asset_type":"cloudresourcemanager.googleapis.com/Project","ancestors":["projects/111","organizations/7"],"resource":{"data":{"projectId":"app-prod","projectNumber":"111","lifecycleState":"ACTIVE"}}}
+`
+	path := filepath.Join(t.TempDir(), "assets.jsonl")
+	if err := os.WriteFile(path, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := loadAssetExport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, org := range src.organizations() {
+		ids = append(ids, org.id)
+	}
+	if strings.Join(ids, ",") != "42,7" {
+		t.Fatalf("expected organizations from org policy records and ancestors, got %v", ids)
+	}
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/execx"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestChecksAgainstCassettes(t *testing.T) {
+	cases := []struct {
+		cassette string
+		check    string
+		opts     Options
+		want     []string
+		note     string
+	}{
+		{
+			cassette: "api_keys",
+			check:    "api-keys",
+			want:     []string{"gcp.api_key.missing_api_targets", "gcp.api_key.missing_environment_restrictions", "gcp.api_key.unrestricted"},
+		},
+		{
+			cassette: "service_account_keys",
+			check:    "service-account-keys",
+			want:     []string{"gcp.sa_key.no_expiry", "gcp.sa_key.stale_review"},
+		},
+		{
+			cassette: "org_policies",
+			check:    "org-policies",
+			opts: Options{OrgPolicyBaseline: []model.OrgPolicyRule{
+				withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/compute.requireOsLogin", Enforced: boolPtr(true)}),
+				withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/iam.disableServiceAccountKeyUpload", Enforced: boolPtr(true)}),
+			}},
+			want: []string{"gcp.org_policy.iam.disableServiceAccountKeyUpload"},
+		},
+		{
+			cassette: "essential_contacts",
+			check:    "essential-contacts",
+			opts:     Options{AllowedContactDomains: []string{"example.com"}},
+			want:     []string{"gcp.essential_contacts.category_missing", "gcp.essential_contacts.unapproved_domain"},
+		},
+		{
+			cassette: "default_service_accounts",
+			check:    "default-service-accounts",
+			want:     []string{"gcp.default_sa.broad_access", "gcp.default_sa.in_use"},
+		},
+		{
+			cassette: "iam_recommender",
+			check:    "iam-recommender",
+			want:     []string{"gcp.iam_recommender.excess_permissions"},
+		},
+		{
+			cassette: "billing_budgets",
+			check:    "billing-budgets",
+			want:     []string{"gcp.billing.budget_alerts_unrouted"},
+		},
+		{
+			cassette: "billing_budgets_denied",
+			check:    "billing-budgets",
+			note:     "billing-budgets check skipped: cannot read billing info for demo",
+		},
+		{
+			cassette: "audit_logs",
+			check:    "audit-logs",
+			want: []string{
+				"gcp.audit_logs.admin_activity_not_retained",
+				"gcp.audit_logs.data_access_missing",
+				"gcp.audit_logs.data_access_missing",
+				"gcp.audit_logs.data_access_missing",
+				"gcp.audit_logs.data_access_missing",
+			},
+		},
+		{
+			cassette: "organization_iam",
+			check:    "org-iam",
+			opts:     Options{Organization: "42"},
+			want:     []string{"gcp.iam.primitive_role", "gcp.iam.public_member"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.cassette, func(t *testing.T) {
+			runner, err := execx.NewReplayRunner(filepath.Join("testdata", "cassettes", tc.cassette))
+			if err != nil {
+				t.Fatalf("load cassette: %v", err)
+			}
+			opts := tc.opts
+			opts.Project = "demo"
+			opts.Runner = runner
+			s := New(opts)
+
+			checks := s.checksFor(ScopeProject)
+			if opts.Organization != "" {
+				checks = s.forRoot(s.scanRoot()).checksFor(ScopeOrganization)
+			}
+			var selected []check
+			for _, c := range checks {
+				if c.name == tc.check {
+					selected = append(selected, c)
+				}
+			}
+			if len(selected) != 1 {
+				t.Fatalf("check %q not found", tc.check)
+			}
+
+			var result model.ScanResult
+			s.runChecks(context.Background(), &result, selected)
+			notes := result.Notes
+			var got []string
+			for _, f := range result.Findings {
+				got = append(got, f.ID)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("findings = %v, want %v (notes: %v)", got, tc.want, notes)
+			}
+			if tc.note == "" && len(notes) > 0 {
+				t.Fatalf("unexpected notes: %v", notes)
+			}
+			if tc.note != "" && (len(notes) != 1 || !strings.HasPrefix(notes[0], tc.note)) {
+				t.Fatalf("notes = %v, want prefix %q", notes, tc.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type Scope string
+
+const (
+	ScopeRepo         Scope = "repo"
+	ScopeProject      Scope = "project"
+	ScopeFolder       Scope = "folder"
+	ScopeOrganization Scope = "organization"
+)
+
+type CheckInfo struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Scopes      []Scope  `json:"scopes"`
+	FindingIDs  []string `json:"finding_ids"`
+	Permissions []string `json:"permissions,omitempty"`
+	// BillingPermissions are granted on the project's billing account, so
+	// preflight cannot test them against the scan target.
+	BillingPermissions []string `json:"billing_permissions,omitempty"`
+	// GCloudOnly checks are skipped with the rest backend; Offline checks
+	// also run against Cloud Asset Inventory exports.
+	GCloudOnly bool `json:"gcloud_only,omitempty"`
+	Offline    bool `json:"offline,omitempty"`
+}
+
+func (i CheckInfo) HasScope(scope Scope) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+// Remote reports whether the check reads Google Cloud rather than only the
+// local repository.
+func (i CheckInfo) Remote() bool {
+	for _, scope := range i.Scopes {
+		if scope != ScopeRepo {
+			return true
+		}
+	}
+	return false
+}
+
+type Check interface {
+	Info() CheckInfo
+	Run(ctx context.Context, s *Scanner) ([]model.Finding, error)
+}
+
+type checkFunc struct {
+	info CheckInfo
+	run  func(s *Scanner, ctx context.Context) ([]model.Finding, error)
+	// ids, when set, lists the finding ids the check can report with the
+	// scanner's configuration, for checks whose ids are not fixed.
+	ids func(s *Scanner) []string
+}
+
+func (c checkFunc) Info() CheckInfo { return c.info }
+
+func (c checkFunc) Run(ctx context.Context, s *Scanner) ([]model.Finding, error) {
+	return c.run(s, ctx)
+}
+
+// findingIDs lists the ids c can report in this scan, as recorded on its
+// execution so later comparisons know which findings it evaluated.
+func (s *Scanner) findingIDs(c Check) []string {
+	if f, ok := c.(checkFunc); ok && f.ids != nil {
+		return f.ids(s)
+	}
+	return c.Info().FindingIDs
+}
+
+var registry []Check
+
+func Register(c Check) {
+	id := c.Info().ID
+	if id == "" {
+		panic("scanner: check registered without an ID")
+	}
+	for _, existing := range registry {
+		if existing.Info().ID == id {
+			panic("scanner: duplicate check " + id)
+		}
+	}
+	registry = append(registry, c)
+	sort.SliceStable(registry, func(i, j int) bool { return registry[i].Info().ID < registry[j].Info().ID })
+}
+
+func Checks() []Check {
+	return append([]Check(nil), registry...)
+}
+
+func ValidateCheckIDs(ids []string) error {
+	var unknown []string
+	for _, id := range ids {
+		if _, ok := lookupCheck(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown checks: %s (see gcpsec checks list)", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func lookupCheck(id string) (Check, bool) {
+	for _, c := range registry {
+		if c.Info().ID == id {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+type check struct {
+	name       string
+	findingIDs []string
+	run        func(context.Context) ([]model.Finding, error)
+}
+
+func (s *Scanner) selected(info CheckInfo) bool {
+	if len(s.opts.Checks) > 0 && !slices.Contains(s.opts.Checks, info.ID) {
+		return false
+	}
+	return !slices.Contains(s.opts.SkipChecks, info.ID)
+}
+
+func (s *Scanner) checksFor(scope Scope) []check {
+	var out []check
+	for _, c := range registry {
+		info := c.Info()
+		if !info.HasScope(scope) || !s.selected(info) {
+			continue
+		}
+		if s.opts.AssetExport != "" && !info.Offline && scope != ScopeRepo {
+			continue
+		}
+		run := func(ctx context.Context) ([]model.Finding, error) { return c.Run(ctx, s) }
+		if info.GCloudOnly && s.opts.Backend == BackendREST && s.opts.AssetExport == "" {
+			run = func(context.Context) ([]model.Finding, error) {
+				return nil, skipCheck(reasonUnsupportedBackend, "not available with the rest backend; use --backend=gcloud")
+			}
+		}
+		out = append(out, check{name: info.ID, findingIDs: s.findingIDs(c), run: run})
+	}
+	return out
+}
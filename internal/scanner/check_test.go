@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestRegisteredChecksDescribeThemselves(t *testing.T) {
+	if len(Checks()) == 0 {
+		t.Fatal("expected registered checks")
+	}
+	for _, c := range Checks() {
+		info := c.Info()
+		if info.Title == "" || info.Category == "" || info.Description == "" || len(info.Scopes) == 0 || len(info.FindingIDs) == 0 {
+			t.Errorf("check %s is missing metadata: %+v", info.ID, info)
+		}
+		if !info.HasScope(ScopeRepo) && len(info.Permissions) == 0 {
+			t.Errorf("remote check %s declares no permissions", info.ID)
+		}
+	}
+}
+
+func TestChecksForHonoursSelection(t *testing.T) {
+	names := func(checks []check) string {
+		var out []string
+		for _, c := range checks {
+			out = append(out, c.name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	s := New(Options{Project: "demo", Checks: []string{"api-keys", "org-policies", "org-iam"}})
+	if got := names(s.checksFor(ScopeProject)); got != "api-keys,org-policies" {
+		t.Fatalf("unexpected project checks: %s", got)
+	}
+
+	s = New(Options{Project: "demo", SkipChecks: []string{"billing-budgets"}})
+	if got := names(s.checksFor(ScopeProject)); strings.Contains(got, "billing-budgets") || !strings.Contains(got, "audit-logs") {
+		t.Fatalf("unexpected project checks: %s", got)
+	}
+
+	if err := ValidateCheckIDs([]string{"api-keys", "nope"}); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected unknown check error, got %v", err)
+	}
+}
+
+func TestScanRecordsSkippedRemoteChecksWithoutTarget(t *testing.T) {
+	cases := []struct {
+		name   string
+		opts   Options
+		reason string
+	}{
+		{"no target", Options{Runner: stubRunner{}, Checks: []string{"api-keys", "org-iam"}}, reasonNoTarget},
+		{"no gcloud", Options{Project: "demo", Runner: missingGCloud{}, Checks: []string{"api-keys", "org-iam"}}, reasonGCloudNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := New(tc.opts).Scan(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Executions) != 2 {
+				t.Fatalf("expected two executions, got %+v", result.Executions)
+			}
+			for _, e := range result.Executions {
+				if e.Status != model.CheckSkipped || e.Reason != tc.reason {
+					t.Fatalf("expected %s to be skipped with %s, got %+v", e.Check, tc.reason, e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+// Reason codes recorded in model.CheckExecution.Reason.
+const (
+	reasonNoFindings         = "no_findings"
+	reasonFindings           = "findings_reported"
+	reasonPartialResults     = "partial_results"
+	reasonCheckError         = "check_error"
+	reasonSchemaMismatch     = "schema_mismatch"
+	reasonPermissionDenied   = "permission_denied"
+	reasonBillingDisabled    = "billing_disabled"
+	reasonNotInExport        = "not_in_export"
+	reasonUnsupportedBackend = "unsupported_backend"
+	reasonNoTarget           = "no_target"
+	reasonGCloudNotFound     = "gcloud_not_found"
+)
+
+// Error categories recorded in model.CheckExecution.ErrorCategory.
+const (
+	categoryPermissionDenied = "permission_denied"
+	categoryAPIDisabled      = "api_disabled"
+	categoryNotFound         = "not_found"
+	categoryQuotaExceeded    = "quota_exceeded"
+	categoryUnauthenticated  = "unauthenticated"
+	categoryTimeout          = "timeout"
+	categorySchemaMismatch   = "schema_mismatch"
+	categoryUnknown          = "unknown"
+)
+
+type execStatsKey struct{}
+
+type execStats struct {
+	resources atomic.Int64
+}
+
+func withExecStats(ctx context.Context) (context.Context, *execStats) {
+	stats := &execStats{}
+	return context.WithValue(ctx, execStatsKey{}, stats), stats
+}
+
+// evaluated records that the running check examined n resources.
+func evaluated(ctx context.Context, n int) {
+	if stats, ok := ctx.Value(execStatsKey{}).(*execStats); ok {
+		stats.resources.Add(int64(n))
+	}
+}
+
+func errorCategory(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case gcpapi.IsSchemaError(err):
+		return categorySchemaMismatch
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "deadline exceeded"):
+		return categoryTimeout
+	case isAPIDisabled(err):
+		return categoryAPIDisabled
+	case isPermissionDenied(err):
+		return categoryPermissionDenied
+	case isQuotaExceeded(err):
+		return categoryQuotaExceeded
+	case isNotFound(err):
+		return categoryNotFound
+	case isUnauthenticated(err):
+		return categoryUnauthenticated
+	default:
+		return categoryUnknown
+	}
+}
+
+func isUnauthenticated(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNAUTHENTICATED") ||
+		strings.Contains(msg, "HTTPError 401") ||
+		strings.Contains(msg, "gcloud auth login")
+}
+
+func (s *Scanner) target() string {
+	switch {
+	case s.opts.Project != "":
+		return "projects/" + s.opts.Project
+	case s.opts.Organization != "" || s.opts.Folder != "":
+		return s.scanRoot().name()
+	default:
+		return s.opts.RepoPath
+	}
+}
+
+// partialCategory reports the category shared by every resource error, or
+// unknown when they differ.
+func partialCategory(p *partialError) string {
+	category := ""
+	for _, cause := range p.causes {
+		c := errorCategory(cause)
+		if category != "" && c != category {
+			return categoryUnknown
+		}
+		category = c
+	}
+	return category
+}
+
+func executionStatus(findings int) (model.CheckStatus, string) {
+	if findings > 0 {
+		return model.CheckFailed, reasonFindings
+	}
+	return model.CheckPassed, reasonNoFindings
+}
@@ -1,17 +1,46 @@
 package scanner
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-)
+	"strings"
 
-func (s *Scanner) gcloudJSON(ctx context.Context, args ...string) ([]map[string]any, error) {
-	cmdCtx, cancel := s.cmdCtx(ctx)
-	defer cancel()
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/ratelimit"
+)
 
+func (s *Scanner) gcloudOutput(ctx context.Context, args ...string) ([]byte, error) {
 	full := append(args, "--format=json")
-	out, err := s.runner.Run(cmdCtx, "gcloud", full...)
+	var out []byte
+	err := ratelimit.Retry(ctx, s.opts.Backoff, isQuotaExceeded, func() error {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		cmdCtx, cancel := s.cmdCtx(ctx)
+		defer cancel()
+
+		var err error
+		out, err = s.runner.Run(cmdCtx, "gcloud", full...)
+		return err
+	})
+	return out, err
+}
+
+func (s *Scanner) gcloudDecode(ctx context.Context, out any, args ...string) error {
+	raw, err := s.gcloudOutput(ctx, args...)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	return gcpapi.Decode(raw, out)
+}
+
+func (s *Scanner) gcloudJSON(ctx context.Context, args ...string) ([]map[string]any, error) {
+	out, err := s.gcloudOutput(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +72,64 @@ func asSlice(v any) []any {
 	return s
 }
 
+func stringSlice(v any) []string {
+	var out []string
+	for _, item := range asSlice(v) {
+		if s := asString(item); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func stringMap(v any) map[string]string {
+	out := map[string]string{}
+	for k, val := range asMap(v) {
+		out[k] = asString(val)
+	}
+	return out
+}
+
 func asBool(v any) bool {
 	b, _ := v.(bool)
 	return b
 }
+
+func isPermissionDenied(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "PERMISSION_DENIED") ||
+		strings.Contains(msg, "does not have permission") ||
+		strings.Contains(msg, "HTTPError 403")
+}
+
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "NOT_FOUND") || strings.Contains(msg, "HTTPError 404")
+}
+
+func isQuotaExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "RESOURCE_EXHAUSTED") ||
+		strings.Contains(msg, "RATE_LIMIT_EXCEEDED") ||
+		strings.Contains(msg, "Quota exceeded") ||
+		strings.Contains(msg, "HTTPError 429")
+}
+
+func isAPIDisabled(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "SERVICE_DISABLED") ||
+		strings.Contains(msg, "has not been used in project") ||
+		strings.Contains(msg, "is not enabled")
+}
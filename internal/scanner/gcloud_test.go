@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+	"github.com/Andrei-Barwood/gcpsec/internal/ratelimit"
+)
+
+type quotaRunner struct {
+	stubRunner
+	mu       sync.Mutex
+	failures map[string]int
+	delays   map[string]time.Duration
+}
+
+func (r *quotaRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	r.mu.Lock()
+	fail := r.failures[key] > 0
+	if fail {
+		r.failures[key]--
+	}
+	r.mu.Unlock()
+	if fail {
+		return nil, errors.New("ERROR: (gcloud) RESOURCE_EXHAUSTED: Quota exceeded for quota metric 'Read requests'")
+	}
+	time.Sleep(r.delays[key])
+	return r.stubRunner.Run(ctx, name, args...)
+}
+
+func TestServiceAccountKeysRetriesQuotaAndKeepsOrder(t *testing.T) {
+	runner := &quotaRunner{
+		stubRunner: stubRunner{},
+		failures:   map[string]int{},
+		delays:     map[string]time.Duration{},
+	}
+	var accounts []string
+	for i := 0; i < 6; i++ {
+		email := fmt.Sprintf("sa-%d@demo.iam.gserviceaccount.com", i)
+		accounts = append(accounts, fmt.Sprintf(`{"email": %q}`, email))
+		key := "gcloud iam service-accounts keys list --iam-account " + email + " --managed-by user --project demo --format=json"
+		runner.stubRunner[key] = fmt.Sprintf(`[{"name": "projects/demo/serviceAccounts/%s/keys/k%d", "validAfterTime": "2020-01-01T00:00:00Z"}]`, email, i)
+		runner.delays[key] = time.Duration(6-i) * time.Millisecond
+		if i%2 == 0 {
+			runner.failures[key] = 2
+		}
+	}
+	runner.stubRunner["gcloud iam service-accounts list --project demo --format=json"] = "[" + strings.Join(accounts, ",") + "]"
+
+	s := New(Options{
+		Project:     "demo",
+		Runner:      runner,
+		Concurrency: 4,
+		RateLimit:   1000,
+		Backoff:     ratelimit.Backoff{Attempts: 3, Base: time.Millisecond, Max: time.Millisecond},
+	})
+	findings, err := s.scanServiceAccountKeys(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	var resources []string
+	for _, f := range findings {
+		if f.ID == "gcp.sa_key.no_expiry" {
+			resources = append(resources, f.Resource)
+		}
+	}
+	if len(resources) != 6 {
+		t.Fatalf("expected 6 no_expiry findings, got %v", resources)
+	}
+	for i, resource := range resources {
+		if !strings.HasSuffix(resource, fmt.Sprintf("/keys/k%d", i)) {
+			t.Fatalf("expected findings in account order, got %v", resources)
+		}
+	}
+}
+
+func TestGCloudGivesUpAfterQuotaRetries(t *testing.T) {
+	key := "gcloud services api-keys list --project demo --format=json"
+	runner := &quotaRunner{
+		stubRunner: stubRunner{key: "[]"},
+		failures:   map[string]int{key: 5},
+	}
+	s := New(Options{
+		Project: "demo",
+		Runner:  runner,
+		Backoff: ratelimit.Backoff{Attempts: 2, Base: time.Millisecond, Max: time.Millisecond},
+	})
+	_, err := s.scanAPIKeys(context.Background())
+	if err == nil || !isQuotaExceeded(err) {
+		t.Fatalf("expected quota error after retries, got %v", err)
+	}
+	if runner.failures[key] != 3 {
+		t.Fatalf("expected 2 attempts, got %d", 5-runner.failures[key])
+	}
+}
+
+func TestServiceAccountKeysReportsPartialResults(t *testing.T) {
+	runner := stubRunner{
+		"gcloud iam service-accounts list --project demo --format=json":                                                                       `[{"email": "denied@demo.iam.gserviceaccount.com"}, {"email": "app@demo.iam.gserviceaccount.com"}]`,
+		"gcloud iam service-accounts keys list --iam-account app@demo.iam.gserviceaccount.com --managed-by user --project demo --format=json": `[{"name": "projects/demo/serviceAccounts/app/keys/k1", "validAfterTime": "2020-01-01T00:00:00Z"}]`,
+	}
+	s := New(Options{Project: "demo", Runner: runner, Checks: []string{"service-account-keys"}})
+
+	result, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(result.Findings) == 0 {
+		t.Fatalf("expected findings for the readable account, got none (notes: %v)", result.Notes)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected one resource error, got %+v", result.Errors)
+	}
+	e := result.Errors[0]
+	if e.Check != "service-account-keys" || e.Project != "demo" || e.Resource != "projects/demo/serviceAccounts/denied@demo.iam.gserviceaccount.com" {
+		t.Fatalf("unexpected resource error: %+v", e)
+	}
+	if len(result.Notes) != 1 || !strings.HasPrefix(result.Notes[0], "service-account-keys check incomplete") {
+		t.Fatalf("expected incomplete note, got %v", result.Notes)
+	}
+	if len(result.Executions) != 1 {
+		t.Fatalf("expected one execution record, got %+v", result.Executions)
+	}
+	exec := result.Executions[0]
+	if exec.Status != model.CheckErrored || exec.Reason != reasonPartialResults || exec.ErrorCategory != categoryNotFound {
+		t.Fatalf("unexpected execution record: %+v", exec)
+	}
+	if exec.ResourcesEvaluated != 1 || exec.Findings != len(result.Findings) || exec.Target != "projects/demo" {
+		t.Fatalf("unexpected execution counts: %+v", exec)
+	}
+}
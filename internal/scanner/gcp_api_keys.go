@@ -3,27 +3,57 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 )
 
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "api-keys",
+			Title:       "API Key Restrictions",
+			Category:    "Credentials",
+			Description: "Flags API keys without API targets or environment (referrer, IP, app) restrictions.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs: []string{
+				"gcp.api_key.unrestricted",
+				"gcp.api_key.missing_api_targets",
+				"gcp.api_key.missing_environment_restrictions",
+			},
+			Permissions: []string{"apikeys.keys.list"},
+			Offline:     true,
+		},
+		run: (*Scanner).scanAPIKeys,
+	})
+}
+
 func (s *Scanner) scanAPIKeys(ctx context.Context) ([]model.Finding, error) {
-	keys, err := s.gcloudJSON(ctx, "services", "api-keys", "list", "--project", s.opts.Project)
+	keys, err := s.src.apiKeys(ctx, s.opts.Project)
 	if err != nil {
 		return nil, err
 	}
 
+	evaluated(ctx, len(keys))
 	findings := make([]model.Finding, 0)
 	for _, key := range keys {
-		name := asString(key["name"])
-		displayName := asString(key["displayName"])
-		resource := displayName
-		if resource == "" {
-			resource = name
+		// Display names can be edited, so findings point at the immutable
+		// key id and only mention the display name.
+		label := key.DisplayName
+		if label == "" {
+			label = key.Name
+		}
+		resource := label
+		if i := strings.LastIndex(key.Name, "/keys/"); i >= 0 {
+			resource = key.Name[i+1:]
+		}
+		metadata := func() map[string]string {
+			return map[string]string{"key_name": key.Name, "display_name": key.DisplayName}
 		}
 
-		restrictions := asMap(key["restrictions"])
-		if len(restrictions) == 0 {
+		hasAPITargets := key.Restrictions.HasAPITargets()
+		hasEnvRestrictions := key.Restrictions.HasEnvironmentRestrictions()
+		if !hasAPITargets && !hasEnvRestrictions {
 			findings = append(findings, model.Finding{
 				ID:       "gcp.api_key.unrestricted",
 				Check:    "API Key Restrictions",
@@ -31,20 +61,15 @@ func (s *Scanner) scanAPIKeys(ctx context.Context) ([]model.Finding, error) {
 				Summary:  "API key has no restrictions",
 				Description: fmt.Sprintf(
 					"API key `%s` does not define API or environment restrictions.",
-					resource,
+					label,
 				),
 				Resource:       resource,
 				Recommendation: "Restrict this key to specific APIs and add environment restrictions (IP, referrer, Android/iOS app, etc.).",
+				Metadata:       metadata(),
 			})
 			continue
 		}
 
-		hasAPITargets := len(asSlice(restrictions["apiTargets"])) > 0
-		hasEnvRestrictions := len(asMap(restrictions["browserKeyRestrictions"])) > 0 ||
-			len(asMap(restrictions["serverKeyRestrictions"])) > 0 ||
-			len(asMap(restrictions["androidKeyRestrictions"])) > 0 ||
-			len(asMap(restrictions["iosKeyRestrictions"])) > 0
-
 		if !hasAPITargets {
 			findings = append(findings, model.Finding{
 				ID:       "gcp.api_key.missing_api_targets",
@@ -53,10 +78,11 @@ func (s *Scanner) scanAPIKeys(ctx context.Context) ([]model.Finding, error) {
 				Summary:  "API key is missing API-level restrictions",
 				Description: fmt.Sprintf(
 					"API key `%s` has environment restrictions but no API targets.",
-					resource,
+					label,
 				),
 				Resource:       resource,
 				Recommendation: "Limit the key to only required APIs (for example, Maps JavaScript API only).",
+				Metadata:       metadata(),
 			})
 		}
 
@@ -68,10 +94,11 @@ func (s *Scanner) scanAPIKeys(ctx context.Context) ([]model.Finding, error) {
 				Summary:  "API key is missing environment restrictions",
 				Description: fmt.Sprintf(
 					"API key `%s` is API-restricted but not bound to an environment.",
-					resource,
+					label,
 				),
 				Resource:       resource,
 				Recommendation: "Add referrer/IP/mobile app restrictions so the key cannot be reused from unauthorized environments.",
+				Metadata:       metadata(),
 			})
 		}
 	}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestScanAPIKeysRejectsUnexpectedSchema(t *testing.T) {
+	runner := stubRunner{
+		"gcloud services api-keys list --project demo --format=json": `[{"name": "projects/1/locations/global/keys/k1", "displayName": "web", "restrictions": [{"apiTargets": [{"service": "maps.googleapis.com"}]}]}]`,
+	}
+	s := New(Options{Project: "demo", Runner: runner})
+
+	var result model.ScanResult
+	s.runChecks(context.Background(), &result, []check{{name: "api key restrictions", run: s.scanAPIKeys}})
+	notes := result.Notes
+	if len(result.Findings) != 0 {
+		t.Fatalf("expected no guessed findings, got %+v", result.Findings)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "skipped") || !strings.Contains(notes[0], "APIKey schema") {
+		t.Fatalf("expected schema note, got %v", notes)
+	}
+}
+
+func TestScanAPIKeysToleratesNewFields(t *testing.T) {
+	runner := stubRunner{
+		"gcloud services api-keys list --project demo --format=json": `[{"name": "projects/1/locations/global/keys/k1", "displayName": "web", "keyRotation": {"period": "90d"}, "restrictions": {"apiTargets": [{"service": "maps.googleapis.com"}], "serverKeyRestrictions": {"allowedIps": ["10.0.0.1"]}}}]`,
+	}
+	findings, err := New(Options{Project: "demo", Runner: runner}).scanAPIKeys(context.Background())
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("expected the unknown field to be ignored, got %+v (%v)", findings, err)
+	}
+}
+
+func TestScanAPIKeysClassifiesRestrictions(t *testing.T) {
+	runner := stubRunner{
+		"gcloud services api-keys list --project demo --format=json": `[
+			{"name": "projects/1/locations/global/keys/open", "displayName": "open"},
+			{"name": "projects/1/locations/global/keys/ip", "displayName": "ip", "restrictions": {"serverKeyRestrictions": {"allowedIps": ["10.0.0.1"]}}},
+			{"name": "projects/1/locations/global/keys/full", "displayName": "full", "restrictions": {"apiTargets": [{"service": "maps.googleapis.com"}], "browserKeyRestrictions": {"allowedReferrers": ["https://example.com/*"]}}}
+		]`,
+	}
+	s := New(Options{Project: "demo", Runner: runner})
+
+	findings, err := s.scanAPIKeys(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range findings {
+		got[f.Resource] = f.ID
+	}
+	if len(findings) != 2 || got["keys/open"] != "gcp.api_key.unrestricted" || got["keys/ip"] != "gcp.api_key.missing_api_targets" {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestScanAPIKeysFingerprintSurvivesRename(t *testing.T) {
+	scan := func(displayName string) model.Finding {
+		t.Helper()
+		runner := stubRunner{
+			"gcloud services api-keys list --project demo --format=json": `[{"name": "projects/1/locations/global/keys/abc", "displayName": "` + displayName + `"}]`,
+		}
+		findings, err := New(Options{Project: "demo", Runner: runner}).scanAPIKeys(context.Background())
+		if err != nil || len(findings) != 1 {
+			t.Fatalf("expected one finding, got %+v (%v)", findings, err)
+		}
+		return findings[0]
+	}
+
+	before, after := scan("maps-prod"), scan("maps-prod-renamed")
+	if model.Fingerprint(before) != model.Fingerprint(after) {
+		t.Fatalf("renaming the key changed its fingerprint: %+v vs %+v", before, after)
+	}
+	if !strings.Contains(after.Description, "maps-prod-renamed") {
+		t.Fatalf("expected the description to mention the display name, got %q", after.Description)
+	}
+}
@@ -0,0 +1,329 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+const defaultAdminActivityRetentionDays = 400
+
+var auditedServices = []struct {
+	service  string
+	severity model.Severity
+}{
+	{service: "iam.googleapis.com", severity: model.SeverityMedium},
+	{service: "secretmanager.googleapis.com", severity: model.SeverityMedium},
+	{service: "cloudkms.googleapis.com", severity: model.SeverityMedium},
+	{service: "allServices", severity: model.SeverityLow},
+}
+
+var dataAccessLogTypes = []string{"DATA_READ", "DATA_WRITE"}
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "audit-logs",
+			Title:       "Audit Logging",
+			Category:    "Logging",
+			Description: "Checks Data Access audit log coverage, exemptions and Admin Activity retention.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs: []string{
+				"gcp.audit_logs.data_access_missing",
+				"gcp.audit_logs.exempted_members",
+				"gcp.audit_logs.admin_activity_not_retained",
+			},
+			Permissions: []string{"resourcemanager.projects.getIamPolicy", "resourcemanager.projects.get", "logging.sinks.list", "logging.buckets.list"},
+			GCloudOnly:  true,
+		},
+		run: (*Scanner).scanAuditLogs,
+	})
+}
+
+func (s *Scanner) scanAuditLogs(ctx context.Context) ([]model.Finding, error) {
+	policy, err := s.projectIAMPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluated(ctx, 1)
+	var partial partialError
+	ancestors, ancestorsErr := s.projectAncestors(ctx)
+	if ancestorsErr != nil {
+		partial.add("projects/"+s.opts.Project, fmt.Errorf("cannot read ancestors: %w", ancestorsErr))
+	}
+
+	configs, inherited := s.inheritedAuditConfigs(ctx, ancestors, &partial)
+	configs = append(configs, asSlice(policy["auditConfigs"])...)
+	findings := auditConfigFindings(s.opts.Project, configs, inherited && ancestorsErr == nil)
+
+	if !s.adminActivityRetained(ctx, ancestors, &partial) && ancestorsErr == nil {
+		findings = append(findings, model.Finding{
+			ID:             "gcp.audit_logs.admin_activity_not_retained",
+			Check:          "Audit Logging",
+			Severity:       model.SeverityLow,
+			Summary:        "Admin Activity logs are only kept for the default retention",
+			Description:    fmt.Sprintf("Project `%s` does not route audit logs to an external sink or to a log bucket retaining them beyond %d days.", s.opts.Project, defaultAdminActivityRetentionDays),
+			Resource:       s.opts.Project,
+			Recommendation: "Route `cloudaudit.googleapis.com` logs to a long-retention bucket, BigQuery dataset or an organization-level sink.",
+		})
+	}
+
+	return findings, partial.err()
+}
+
+// inheritedAuditConfigs collects the auditConfigs of the folders and
+// organization above the project, which apply to it as well. It reports
+// false when one of them could not be read.
+func (s *Scanner) inheritedAuditConfigs(ctx context.Context, ancestors []resourceRef, partial *partialError) ([]any, bool) {
+	var configs []any
+	complete := true
+	for _, ref := range ancestors {
+		if ref.kind != "organization" && ref.kind != "folder" {
+			continue
+		}
+		policy, err := s.src.iamPolicy(ctx, ref)
+		if err != nil {
+			partial.add(ref.name(), fmt.Errorf("cannot read inherited audit configs: %w", err))
+			complete = false
+			continue
+		}
+		configs = append(configs, asSlice(policy["auditConfigs"])...)
+	}
+	return configs, complete
+}
+
+// auditConfigFindings evaluates the project's effective audit configs, its
+// own merged with those it inherits. Missing log types are only reported
+// when every inherited config could be read.
+func auditConfigFindings(project string, configs []any, complete bool) []model.Finding {
+	enabled := map[string]map[string]bool{}
+	exempted := map[string]map[string][]string{}
+	for _, item := range configs {
+		cfg := asMap(item)
+		service := asString(cfg["service"])
+		if enabled[service] == nil {
+			enabled[service] = map[string]bool{}
+			exempted[service] = map[string][]string{}
+		}
+		for _, l := range asSlice(cfg["auditLogConfigs"]) {
+			logCfg := asMap(l)
+			logType := asString(logCfg["logType"])
+			enabled[service][logType] = true
+			exempted[service][logType] = append(exempted[service][logType], stringSlice(logCfg["exemptedMembers"])...)
+		}
+	}
+
+	findings := make([]model.Finding, 0)
+	for _, target := range auditedServices {
+		var missing []string
+		for _, logType := range dataAccessLogTypes {
+			if !enabled[target.service][logType] && !enabled["allServices"][logType] {
+				missing = append(missing, logType)
+			}
+		}
+		if len(missing) > 0 && complete {
+			findings = append(findings, model.Finding{
+				ID:       "gcp.audit_logs.data_access_missing",
+				Check:    "Audit Logging",
+				Severity: target.severity,
+				Summary:  "Data Access audit logs are not enabled",
+				Description: fmt.Sprintf(
+					"Project `%s` does not log %s for `%s`, so credential use cannot be reconstructed after a leak.",
+					project,
+					strings.Join(missing, " and "),
+					target.service,
+				),
+				Resource:       project,
+				Recommendation: "Enable DATA_READ and DATA_WRITE audit logs for this service in the project (or organization) IAM policy.",
+				Metadata: map[string]string{
+					"service":   target.service,
+					"log_types": strings.Join(missing, ","),
+				},
+			})
+		}
+
+		for _, logType := range dataAccessLogTypes {
+			members := exempted[target.service][logType]
+			if len(members) == 0 {
+				continue
+			}
+			sort.Strings(members)
+			findings = append(findings, model.Finding{
+				ID:       "gcp.audit_logs.exempted_members",
+				Check:    "Audit Logging",
+				Severity: model.SeverityLow,
+				Summary:  "Principals are exempted from Data Access audit logging",
+				Description: fmt.Sprintf(
+					"%s logging for `%s` exempts %s.",
+					logType,
+					target.service,
+					strings.Join(members, ", "),
+				),
+				Resource:       project,
+				Recommendation: "Remove audit log exemptions unless they are documented and reviewed; exempted principals leave no trail.",
+				Metadata: map[string]string{
+					"service":  target.service,
+					"log_type": logType,
+					"members":  strings.Join(members, ","),
+				},
+			})
+		}
+	}
+
+	return findings
+}
+
+// adminActivityRetained looks for a sink exporting the project's audit logs
+// beyond the default retention: one of the project's own sinks, or an
+// aggregated sink (includeChildren) on a folder or organization above it.
+// Sinks or buckets that cannot be read are reported through partial and
+// count as retained: an unreadable sink proves nothing either way, so the
+// check notes it instead of reporting a finding.
+func (s *Scanner) adminActivityRetained(ctx context.Context, ancestors []resourceRef, partial *partialError) bool {
+	project := "projects/" + s.opts.Project
+	sinks, err := s.gcloudJSON(ctx, "logging", "sinks", "list", "--project", s.opts.Project)
+	if err != nil {
+		partial.add(project, fmt.Errorf("cannot list log sinks: %w", err))
+		return true
+	}
+	exported, buckets := auditSinkDestinations(sinks, false)
+	if exported {
+		return true
+	}
+	ok, err := s.retainingBucket(ctx, buckets)
+	if err != nil {
+		partial.add(project, fmt.Errorf("cannot read log bucket retention: %w", err))
+		return true
+	}
+	if ok {
+		return true
+	}
+
+	unknown := false
+	for _, ref := range ancestors {
+		if ref.kind != "organization" && ref.kind != "folder" {
+			continue
+		}
+		sinks, err := s.gcloudJSON(ctx, "logging", "sinks", "list", "--"+ref.kind, ref.id)
+		if err != nil {
+			partial.add(ref.name(), fmt.Errorf("cannot list aggregated sinks: %w", err))
+			unknown = true
+			continue
+		}
+		exported, buckets := auditSinkDestinations(sinks, true)
+		if exported {
+			return true
+		}
+		ok, err := s.retainingBucket(ctx, buckets)
+		if err != nil {
+			partial.add(ref.name(), fmt.Errorf("cannot read log bucket retention: %w", err))
+			unknown = true
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+	return unknown
+}
+
+// auditSinkDestinations reports whether a sink exports audit logs outside
+// Cloud Logging, and otherwise the log buckets audit logs are routed to.
+// With aggregated set, only sinks that include child resources count.
+func auditSinkDestinations(sinks []map[string]any, aggregated bool) (bool, []string) {
+	var buckets []string
+	for _, sink := range sinks {
+		name := asString(sink["name"])
+		if name == "_Default" || name == "_Required" || asBool(sink["disabled"]) {
+			continue
+		}
+		if aggregated && !asBool(sink["includeChildren"]) {
+			continue
+		}
+		if !selectsAuditLogs(asString(sink["filter"])) {
+			continue
+		}
+		dest := asString(sink["destination"])
+		if !strings.HasPrefix(dest, "logging.googleapis.com/") {
+			return true, nil
+		}
+		buckets = append(buckets, strings.TrimPrefix(dest, "logging.googleapis.com/"))
+	}
+	return false, buckets
+}
+
+// selectsAuditLogs reports whether a sink filter routes audit logs. An empty
+// filter routes everything; otherwise some clause has to match
+// cloudaudit.googleapis.com without being negated, so a filter that only
+// excludes audit logs (NOT logName:"...cloudaudit...") does not count.
+func selectsAuditLogs(filter string) bool {
+	if strings.TrimSpace(filter) == "" {
+		return true
+	}
+	clauses := strings.Split(strings.NewReplacer(" AND ", "\n", " OR ", "\n").Replace(filter), "\n")
+	depth, negatedAt := 0, 0
+	for _, clause := range clauses {
+		c := strings.TrimSpace(clause)
+		for strings.HasPrefix(c, "(") {
+			c = strings.TrimSpace(c[1:])
+			depth++
+		}
+		negated := strings.HasPrefix(c, "NOT ") || strings.HasPrefix(c, "-")
+		if negated {
+			c = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(c, "NOT "), "-"))
+		}
+		if negated && strings.HasPrefix(c, "(") && negatedAt == 0 {
+			negatedAt = depth + 1
+		}
+		depth += strings.Count(c, "(")
+		if strings.Contains(c, "cloudaudit.googleapis.com") && !negated && negatedAt == 0 && !strings.Contains(c, "!=") {
+			return true
+		}
+		depth -= strings.Count(c, ")")
+		if negatedAt > 0 && depth < negatedAt {
+			negatedAt = 0
+		}
+	}
+	return false
+}
+
+// retainingBucket reports whether one of the given log buckets keeps logs
+// beyond the default retention. Buckets are listed in the project that owns
+// them, which for aggregated sinks is usually a central logging project.
+func (s *Scanner) retainingBucket(ctx context.Context, destinations []string) (bool, error) {
+	byProject := map[string][]string{}
+	var projects []string
+	for _, dest := range destinations {
+		project := s.opts.Project
+		if parts := strings.Split(dest, "/"); len(parts) > 1 && parts[0] == "projects" {
+			project = parts[1]
+		}
+		if _, ok := byProject[project]; !ok {
+			projects = append(projects, project)
+		}
+		byProject[project] = append(byProject[project], dest)
+	}
+
+	for _, project := range projects {
+		buckets, err := s.gcloudJSON(ctx, "logging", "buckets", "list", "--project", project)
+		if err != nil {
+			return false, err
+		}
+		for _, bucket := range buckets {
+			days, _ := strconv.Atoi(fmt.Sprint(bucket["retentionDays"]))
+			if days <= defaultAdminActivityRetentionDays {
+				continue
+			}
+			if slices.Contains(byProject[project], asString(bucket["name"])) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
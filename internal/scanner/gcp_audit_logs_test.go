@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestAuditConfigFindingsHonoursAllServices(t *testing.T) {
+	policy := map[string]any{
+		"auditConfigs": []any{
+			map[string]any{
+				"service": "allServices",
+				"auditLogConfigs": []any{
+					map[string]any{"logType": "DATA_READ"},
+					map[string]any{"logType": "DATA_WRITE"},
+				},
+			},
+			map[string]any{
+				"service": "iam.googleapis.com",
+				"auditLogConfigs": []any{
+					map[string]any{"logType": "DATA_READ", "exemptedMembers": []any{"user:ops@example.com"}},
+				},
+			},
+		},
+	}
+
+	findings := auditConfigFindings("demo-project", asSlice(policy["auditConfigs"]), true)
+	if len(findings) != 1 {
+		t.Fatalf("expected only the exemption finding, got %d: %+v", len(findings), findings)
+	}
+	if findings[0].ID != "gcp.audit_logs.exempted_members" {
+		t.Fatalf("expected exempted_members finding, got %s", findings[0].ID)
+	}
+	if findings[0].Metadata["members"] != "user:ops@example.com" {
+		t.Fatalf("unexpected exempted members: %s", findings[0].Metadata["members"])
+	}
+}
+
+func TestScanAuditLogsHonoursAggregatedSinks(t *testing.T) {
+	base := stubRunner{
+		"gcloud projects get-iam-policy demo --format=json":               `{"auditConfigs": [{"service": "allServices", "auditLogConfigs": [{"logType": "DATA_READ"}, {"logType": "DATA_WRITE"}]}]}`,
+		"gcloud logging sinks list --project demo --format=json":          `[{"name": "_Default", "destination": "logging.googleapis.com/projects/demo/locations/global/buckets/_Default"}]`,
+		"gcloud projects get-ancestors demo --format=json":                `[{"id": "demo", "type": "project"}, {"id": "42", "type": "folder"}, {"id": "123", "type": "organization"}]`,
+		"gcloud logging sinks list --folder 42 --format=json":             `[]`,
+		"gcloud resource-manager folders get-iam-policy 42 --format=json": `{}`,
+		"gcloud organizations get-iam-policy 123 --format=json":           `{}`,
+	}
+	with := func(extra map[string]string) stubRunner {
+		runner := stubRunner{}
+		for k, v := range base {
+			runner[k] = v
+		}
+		for k, v := range extra {
+			runner[k] = v
+		}
+		return runner
+	}
+	retentionIDs := func(findings []model.Finding) []string {
+		var ids []string
+		for _, f := range findings {
+			if f.ID == "gcp.audit_logs.admin_activity_not_retained" {
+				ids = append(ids, f.ID)
+			}
+		}
+		return ids
+	}
+
+	cases := []struct {
+		name   string
+		runner stubRunner
+		want   int
+	}{
+		{
+			name: "organization sink to a long retention bucket",
+			runner: with(map[string]string{
+				"gcloud logging sinks list --organization 123 --format=json":       `[{"name": "org-audit", "includeChildren": true, "filter": "logName:\"cloudaudit.googleapis.com\"", "destination": "logging.googleapis.com/projects/central-logs/locations/global/buckets/audit"}]`,
+				"gcloud logging buckets list --project central-logs --format=json": `[{"name": "projects/central-logs/locations/global/buckets/audit", "retentionDays": 3650}]`,
+			}),
+		},
+		{
+			name: "organization sink excluding audit logs",
+			runner: with(map[string]string{
+				"gcloud logging sinks list --organization 123 --format=json": `[{"name": "org-rest", "includeChildren": true, "filter": "NOT logName:\"cloudaudit.googleapis.com\"", "destination": "bigquery.googleapis.com/projects/central-logs/datasets/rest"}]`,
+			}),
+			want: 1,
+		},
+		{
+			name: "organization sink without includeChildren",
+			runner: with(map[string]string{
+				"gcloud logging sinks list --organization 123 --format=json": `[{"name": "org-only", "destination": "bigquery.googleapis.com/projects/central-logs/datasets/audit"}]`,
+			}),
+			want: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings, err := New(Options{Project: "demo", Runner: tc.runner}).scanAuditLogs(context.Background())
+			if err != nil {
+				t.Fatalf("scan failed: %v", err)
+			}
+			if got := retentionIDs(findings); len(got) != tc.want {
+				t.Fatalf("expected %d retention finding(s), got %+v", tc.want, findings)
+			}
+		})
+	}
+
+	t.Run("unreadable organization sinks", func(t *testing.T) {
+		runner := deniedRunner{stubRunner: base, denied: "gcloud logging sinks list --organization 123 --format=json"}
+		s := New(Options{Project: "demo", Runner: runner})
+		var result model.ScanResult
+		s.runChecks(context.Background(), &result, []check{{name: "audit-logs", run: s.scanAuditLogs}})
+		if got := retentionIDs(result.Findings); len(got) != 0 {
+			t.Fatalf("expected no retention finding when the organization cannot be read, got %+v", result.Findings)
+		}
+		if len(result.Errors) != 1 || result.Errors[0].Resource != "organizations/123" {
+			t.Fatalf("expected a resource error for the organization, got %+v", result.Errors)
+		}
+		if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], "audit-logs check incomplete") {
+			t.Fatalf("expected an incomplete note, got %v", result.Notes)
+		}
+	})
+}
+
+func TestScanAuditLogsMergesInheritedAuditConfigs(t *testing.T) {
+	runner := stubRunner{
+		"gcloud projects get-iam-policy demo --format=json":          `{}`,
+		"gcloud projects get-ancestors demo --format=json":           `[{"id": "demo", "type": "project"}, {"id": "123", "type": "organization"}]`,
+		"gcloud organizations get-iam-policy 123 --format=json":      `{"auditConfigs": [{"service": "allServices", "auditLogConfigs": [{"logType": "DATA_READ"}, {"logType": "DATA_WRITE"}]}]}`,
+		"gcloud logging sinks list --project demo --format=json":     `[]`,
+		"gcloud logging sinks list --organization 123 --format=json": `[{"name": "org-audit", "includeChildren": true, "destination": "storage.googleapis.com/org-audit"}]`,
+	}
+	findings, err := New(Options{Project: "demo", Runner: runner}).scanAuditLogs(context.Background())
+	if err != nil || len(findings) != 0 {
+		t.Fatalf("expected organization audit configs to cover the project, got %+v (%v)", findings, err)
+	}
+}
+
+func TestScanAuditLogsKeepsFindingsWhenSinksAreDenied(t *testing.T) {
+	runner := deniedRunner{
+		stubRunner: stubRunner{
+			"gcloud projects get-iam-policy demo --format=json": `{}`,
+			"gcloud projects get-ancestors demo --format=json":  `[{"id": "demo", "type": "project"}]`,
+		},
+		denied: "gcloud logging sinks list --project demo --format=json",
+	}
+	findings, err := New(Options{Project: "demo", Runner: runner}).scanAuditLogs(context.Background())
+	var partial *partialError
+	if !errors.As(err, &partial) || len(partial.errors) != 1 || partial.errors[0].Resource != "projects/demo" {
+		t.Fatalf("expected a resource error for the project sinks, got %v", err)
+	}
+	if len(findings) != len(auditedServices) {
+		t.Fatalf("expected the audit config findings to be kept, got %+v", findings)
+	}
+}
+
+func TestSelectsAuditLogs(t *testing.T) {
+	cases := map[string]bool{
+		``:                                    true,
+		`logName:"cloudaudit.googleapis.com"`: true,
+		`resource.type="gce_instance" OR logName:"projects/demo/logs/cloudaudit.googleapis.com%2Factivity"`:                             true,
+		`NOT logName:"cloudaudit.googleapis.com"`:                                                                                       false,
+		`-logName:"cloudaudit.googleapis.com"`:                                                                                          false,
+		`logName!="projects/demo/logs/cloudaudit.googleapis.com%2Factivity"`:                                                            false,
+		`severity>=ERROR AND NOT (logName:"cloudaudit.googleapis.com%2Factivity" OR logName:"cloudaudit.googleapis.com%2Fdata_access")`: false,
+		`resource.type="gce_instance"`:                                                                                                  false,
+	}
+	for filter, want := range cases {
+		if got := selectsAuditLogs(filter); got != want {
+			t.Errorf("selectsAuditLogs(%q) = %v, want %v", filter, got, want)
+		}
+	}
+}
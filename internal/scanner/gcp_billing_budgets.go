@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "billing-budgets",
+			Title:       "Billing Budgets",
+			Category:    "Incident Readiness",
+			Description: "Requires a billing budget covering the project with routed threshold alerts.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs: []string{
+				"gcp.billing.no_budget",
+				"gcp.billing.budget_no_thresholds",
+				"gcp.billing.budget_alerts_unrouted",
+			},
+			Permissions:        []string{"resourcemanager.projects.get", "billing.resourceAssociations.list"},
+			BillingPermissions: []string{"billing.budgets.list"},
+			GCloudOnly:         true,
+		},
+		run: (*Scanner).scanBillingBudgets,
+	})
+}
+
+func (s *Scanner) scanBillingBudgets(ctx context.Context) ([]model.Finding, error) {
+	billing, err := s.gcloudJSON(ctx, "billing", "projects", "describe", s.opts.Project)
+	if err != nil {
+		if isPermissionDenied(err) || isAPIDisabled(err) {
+			return nil, skipCheck(reasonPermissionDenied, "cannot read billing info for %s; grant billing.resourceAssociations.list to evaluate budgets", s.opts.Project)
+		}
+		return nil, err
+	}
+	if len(billing) == 0 || !asBool(billing[0]["billingEnabled"]) {
+		return nil, skipCheck(reasonBillingDisabled, "billing is not enabled for %s", s.opts.Project)
+	}
+
+	accountName := asString(billing[0]["billingAccountName"])
+	accountID := strings.TrimPrefix(accountName, "billingAccounts/")
+	if accountID == "" {
+		return nil, skipCheck(reasonPermissionDenied, "billing account for %s is not visible to the scanning identity", s.opts.Project)
+	}
+
+	number, err := s.projectNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.gcloudJSON(ctx, "billing", "budgets", "list", "--billing-account", accountID)
+	if err != nil {
+		if isPermissionDenied(err) || isAPIDisabled(err) {
+			return nil, skipCheck(reasonPermissionDenied, "cannot list budgets on %s; grant billing.budgets.list to evaluate budgets", accountName)
+		}
+		return nil, err
+	}
+
+	evaluated(ctx, len(budgets))
+	findings := make([]model.Finding, 0)
+	scoped := 0
+	for _, budget := range budgets {
+		if !budgetCoversProject(budget, number) {
+			continue
+		}
+		scoped++
+
+		name := asString(budget["displayName"])
+		if name == "" {
+			name = asString(budget["name"])
+		}
+		meta := map[string]string{
+			"billing_account": accountName,
+			"budget":          asString(budget["name"]),
+		}
+
+		if len(asSlice(budget["thresholdRules"])) == 0 {
+			findings = append(findings, model.Finding{
+				ID:             "gcp.billing.budget_no_thresholds",
+				Check:          "Incident Readiness",
+				Severity:       model.SeverityLow,
+				Summary:        "Budget has no alert thresholds",
+				Description:    fmt.Sprintf("Budget `%s` covering `%s` defines no threshold rules, so it never alerts.", name, s.opts.Project),
+				Resource:       asString(budget["name"]),
+				Recommendation: "Add threshold rules (for example 50%, 90% and 100% of actual and forecasted spend).",
+				Metadata:       meta,
+			})
+			continue
+		}
+
+		rule := asMap(budget["notificationsRule"])
+		if asString(rule["pubsubTopic"]) == "" && len(asSlice(rule["monitoringNotificationChannels"])) == 0 {
+			findings = append(findings, model.Finding{
+				ID:             "gcp.billing.budget_alerts_unrouted",
+				Check:          "Incident Readiness",
+				Severity:       model.SeverityLow,
+				Summary:        "Budget alerts are not routed to a channel or Pub/Sub topic",
+				Description:    fmt.Sprintf("Budget `%s` covering `%s` only emails billing admins; no Monitoring channel or Pub/Sub topic is notified.", name, s.opts.Project),
+				Resource:       asString(budget["name"]),
+				Recommendation: "Connect the budget to a Cloud Monitoring notification channel or a Pub/Sub topic watched by the on-call team.",
+				Metadata:       meta,
+			})
+		}
+	}
+
+	if scoped == 0 {
+		findings = append(findings, model.Finding{
+			ID:             "gcp.billing.no_budget",
+			Check:          "Incident Readiness",
+			Severity:       model.SeverityMedium,
+			Summary:        "No billing budget covers this project",
+			Description:    fmt.Sprintf("Billing account `%s` has no budget scoped to `%s`; a leaked key would surface only on the invoice.", accountName, s.opts.Project),
+			Resource:       s.opts.Project,
+			Recommendation: "Create a budget filtered to this project with threshold alerts routed to the on-call team.",
+			Metadata: map[string]string{
+				"billing_account": accountName,
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+func budgetCoversProject(budget map[string]any, projectNumber string) bool {
+	projects := stringSlice(asMap(budget["budgetFilter"])["projects"])
+	if len(projects) == 0 {
+		return true
+	}
+	for _, p := range projects {
+		if p == "projects/"+projectNumber {
+			return true
+		}
+	}
+	return false
+}
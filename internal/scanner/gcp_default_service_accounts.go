@@ -0,0 +1,271 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+var broadProjectRoles = map[string]struct{}{
+	"roles/owner":  {},
+	"roles/editor": {},
+}
+
+type workload struct {
+	kind     string
+	resource string
+	account  string
+	scopes   []string
+}
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "default-service-accounts",
+			Title:       "Default Service Accounts",
+			Category:    "IAM",
+			Description: "Finds workloads running as the Compute Engine or App Engine default service account.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs:  []string{"gcp.default_sa.in_use", "gcp.default_sa.broad_access"},
+			Permissions: []string{
+				"resourcemanager.projects.get",
+				"resourcemanager.projects.getIamPolicy",
+				"compute.instances.list",
+				"container.clusters.list",
+				"run.services.list",
+				"cloudfunctions.functions.list",
+			},
+			GCloudOnly: true,
+		},
+		run: (*Scanner).scanDefaultServiceAccounts,
+	})
+}
+
+func (s *Scanner) scanDefaultServiceAccounts(ctx context.Context) ([]model.Finding, error) {
+	number, err := s.projectNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.projectIAMPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	computeDefault := number + "-compute@developer.gserviceaccount.com"
+	appEngineDefault := s.opts.Project + "@appspot.gserviceaccount.com"
+	roles := rolesByMember(policy)
+
+	workloads, listErr := s.listWorkloads(ctx, computeDefault, appEngineDefault)
+
+	evaluated(ctx, len(workloads))
+	findings := make([]model.Finding, 0)
+	for _, w := range workloads {
+		broad := broadRoles(roles["serviceAccount:"+w.account])
+		isDefault := w.account == computeDefault || w.account == appEngineDefault
+
+		if isDefault {
+			severity := model.SeverityMedium
+			if len(broad) > 0 {
+				severity = model.SeverityHigh
+			}
+			findings = append(findings, model.Finding{
+				ID:       "gcp.default_sa.in_use",
+				Check:    "Default Service Accounts",
+				Severity: severity,
+				Summary:  "Workload runs as a default service account",
+				Description: fmt.Sprintf(
+					"%s `%s` runs as default service account `%s`.",
+					w.kind,
+					w.resource,
+					w.account,
+				),
+				Resource:       w.resource,
+				Recommendation: "Create a dedicated service account with only the roles this workload needs and attach it instead of the default one.",
+				Metadata: map[string]string{
+					"service_account": w.account,
+					"workload_kind":   w.kind,
+					"broad_roles":     strings.Join(broad, ","),
+				},
+			})
+		}
+
+		if len(broad) > 0 && slices.Contains(w.scopes, cloudPlatformScope) {
+			findings = append(findings, model.Finding{
+				ID:       "gcp.default_sa.broad_access",
+				Check:    "Default Service Accounts",
+				Severity: model.SeverityHigh,
+				Summary:  "Workload combines cloud-platform scope with broad project roles",
+				Description: fmt.Sprintf(
+					"%s `%s` uses the `cloud-platform` scope and its service account `%s` holds %s on the project.",
+					w.kind,
+					w.resource,
+					w.account,
+					strings.Join(broad, ", "),
+				),
+				Resource:       w.resource,
+				Recommendation: "Remove Owner/Editor from the workload service account and grant narrowly scoped predefined roles.",
+				Metadata: map[string]string{
+					"service_account": w.account,
+					"workload_kind":   w.kind,
+					"broad_roles":     strings.Join(broad, ","),
+				},
+			})
+		}
+	}
+
+	return findings, listErr
+}
+
+// listWorkloads collects workloads from every product. A product whose API
+// is disabled has none; any other failure is recorded per product so the
+// workloads that could be listed are still evaluated.
+func (s *Scanner) listWorkloads(ctx context.Context, computeDefault, appEngineDefault string) ([]workload, error) {
+	listers := []struct {
+		collection string
+		list       func(context.Context, string, string) ([]workload, error)
+	}{
+		{"instances", s.listComputeInstances},
+		{"clusters", s.listGKENodePools},
+		{"services", s.listCloudRunServices},
+		{"functions", s.listCloudFunctions},
+	}
+
+	var out []workload
+	var partial partialError
+	for _, l := range listers {
+		items, err := l.list(ctx, computeDefault, appEngineDefault)
+		if err != nil {
+			if !isAPIDisabled(err) {
+				partial.add(fmt.Sprintf("projects/%s/%s", s.opts.Project, l.collection), err)
+			}
+			continue
+		}
+		out = append(out, items...)
+	}
+	return out, partial.err()
+}
+
+func (s *Scanner) listComputeInstances(ctx context.Context, _, _ string) ([]workload, error) {
+	instances, err := s.gcloudJSON(ctx, "compute", "instances", "list", "--project", s.opts.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []workload
+	for _, inst := range instances {
+		resource := fmt.Sprintf(
+			"projects/%s/zones/%s/instances/%s",
+			s.opts.Project,
+			path.Base(asString(inst["zone"])),
+			asString(inst["name"]),
+		)
+		for _, item := range asSlice(inst["serviceAccounts"]) {
+			sa := asMap(item)
+			out = append(out, workload{
+				kind:     "Compute Engine instance",
+				resource: resource,
+				account:  asString(sa["email"]),
+				scopes:   stringSlice(sa["scopes"]),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (s *Scanner) listGKENodePools(ctx context.Context, computeDefault, _ string) ([]workload, error) {
+	clusters, err := s.gcloudJSON(ctx, "container", "clusters", "list", "--project", s.opts.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []workload
+	for _, cluster := range clusters {
+		for _, item := range asSlice(cluster["nodePools"]) {
+			pool := asMap(item)
+			config := asMap(pool["config"])
+			account := asString(config["serviceAccount"])
+			if account == "" || account == "default" {
+				account = computeDefault
+			}
+			out = append(out, workload{
+				kind: "GKE node pool",
+				resource: fmt.Sprintf(
+					"projects/%s/locations/%s/clusters/%s/nodePools/%s",
+					s.opts.Project,
+					asString(cluster["location"]),
+					asString(cluster["name"]),
+					asString(pool["name"]),
+				),
+				account: account,
+				scopes:  stringSlice(config["oauthScopes"]),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (s *Scanner) listCloudRunServices(ctx context.Context, computeDefault, _ string) ([]workload, error) {
+	services, err := s.gcloudJSON(ctx, "run", "services", "list", "--project", s.opts.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []workload
+	for _, svc := range services {
+		meta := asMap(svc["metadata"])
+		spec := asMap(asMap(asMap(svc["spec"])["template"])["spec"])
+		account := asString(spec["serviceAccountName"])
+		if account == "" {
+			account = computeDefault
+		}
+		region := asString(asMap(meta["labels"])["cloud.googleapis.com/location"])
+		out = append(out, workload{
+			kind:     "Cloud Run service",
+			resource: fmt.Sprintf("projects/%s/locations/%s/services/%s", s.opts.Project, region, asString(meta["name"])),
+			account:  account,
+		})
+	}
+	return out, nil
+}
+
+func (s *Scanner) listCloudFunctions(ctx context.Context, computeDefault, appEngineDefault string) ([]workload, error) {
+	functions, err := s.gcloudJSON(ctx, "functions", "list", "--project", s.opts.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []workload
+	for _, fn := range functions {
+		account := asString(fn["serviceAccountEmail"])
+		if cfg := asMap(fn["serviceConfig"]); len(cfg) > 0 {
+			account = asString(cfg["serviceAccountEmail"])
+			if account == "" {
+				account = computeDefault
+			}
+		} else if account == "" {
+			account = appEngineDefault
+		}
+		out = append(out, workload{
+			kind:     "Cloud Function",
+			resource: asString(fn["name"]),
+			account:  account,
+		})
+	}
+	return out, nil
+}
+
+func broadRoles(roles []string) []string {
+	var out []string
+	for _, r := range roles {
+		if _, ok := broadProjectRoles[r]; ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
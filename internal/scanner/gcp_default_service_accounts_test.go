@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestScanDefaultServiceAccountsKeepsFindingsWhenAListerFails(t *testing.T) {
+	runner := deniedRunner{
+		stubRunner: stubRunner{
+			"gcloud projects describe demo --format=json":       `{"projectId": "demo", "projectNumber": "123"}`,
+			"gcloud projects get-iam-policy demo --format=json": `{"bindings": [{"role": "roles/editor", "members": ["serviceAccount:123-compute@developer.gserviceaccount.com"]}]}`,
+			"gcloud compute instances list --project demo --format=json": `[{"name": "web", "zone": "zones/us-central1-a", "serviceAccounts": [
+				{"email": "123-compute@developer.gserviceaccount.com", "scopes": ["https://www.googleapis.com/auth/cloud-platform"]}
+			]}]`,
+			"gcloud functions list --project demo --format=json": `[]`,
+		},
+		denied: "gcloud container clusters list --project demo --format=json",
+	}
+	s := New(Options{Project: "demo", Runner: apiDisabledRunner{deniedRunner: runner, disabled: "gcloud run services list --project demo --format=json"}})
+
+	var result model.ScanResult
+	s.runChecks(context.Background(), &result, []check{{name: "default-service-accounts", run: s.scanDefaultServiceAccounts}})
+
+	got := map[string]bool{}
+	for _, f := range result.Findings {
+		got[f.ID] = true
+	}
+	if !got["gcp.default_sa.in_use"] || !got["gcp.default_sa.broad_access"] {
+		t.Fatalf("expected the instance findings to survive, got %+v", result.Findings)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Resource != "projects/demo/clusters" {
+		t.Fatalf("expected only the denied GKE listing as a resource error, got %+v", result.Errors)
+	}
+	if len(result.Executions) != 1 || result.Executions[0].Status != model.CheckErrored || result.Executions[0].ErrorCategory != "permission_denied" {
+		t.Fatalf("unexpected execution record: %+v", result.Executions)
+	}
+}
+
+// apiDisabledRunner fails one command the way gcloud does when the product
+// API is not enabled in the project.
+type apiDisabledRunner struct {
+	deniedRunner
+	disabled string
+}
+
+func (r apiDisabledRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if key := strings.Join(append([]string{name}, args...), " "); key == r.disabled {
+		return nil, errors.New(key + " failed: SERVICE_DISABLED: Cloud Run Admin API has not been used in project demo before or it is disabled")
+	}
+	return r.deniedRunner.Run(ctx, name, args...)
+}
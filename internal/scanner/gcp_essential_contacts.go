@@ -2,33 +2,160 @@ package scanner
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 )
 
+var requiredContactCategories = []struct {
+	category string
+	severity model.Severity
+}{
+	{category: "SECURITY", severity: model.SeverityMedium},
+	{category: "TECHNICAL", severity: model.SeverityLow},
+}
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "essential-contacts",
+			Title:       "Essential Contacts",
+			Category:    "Incident Readiness",
+			Description: "Requires effective SECURITY and TECHNICAL Essential Contacts in approved domains.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs: []string{
+				"gcp.essential_contacts.none",
+				"gcp.essential_contacts.category_missing",
+				"gcp.essential_contacts.unapproved_domain",
+			},
+			Permissions: []string{"essentialcontacts.contacts.list"},
+		},
+		run: (*Scanner).scanEssentialContacts,
+	})
+}
+
+// scanEssentialContacts reads the project's effective contacts for every
+// category (ALL) and then for each required category. A project with no
+// contacts at all gets a single finding; otherwise each uncovered required
+// category is reported, and every contact is held to the allowed domains.
 func (s *Scanner) scanEssentialContacts(ctx context.Context) ([]model.Finding, error) {
-	contacts, err := s.gcloudJSON(
-		ctx,
-		"essential-contacts", "contacts", "list",
-		"--project", s.opts.Project,
-	)
+	all, err := s.src.contacts(ctx, s.opts.Project, "ALL")
 	if err != nil {
 		return nil, err
 	}
+	evaluated(ctx, len(all))
 
-	if len(contacts) > 0 {
-		return nil, nil
+	if len(all) == 0 {
+		categories := make([]string, 0, len(requiredContactCategories))
+		for _, req := range requiredContactCategories {
+			categories = append(categories, req.category)
+		}
+		return []model.Finding{
+			{
+				ID:             "gcp.essential_contacts.none",
+				Check:          "Incident Readiness",
+				Severity:       model.SeverityMedium,
+				Summary:        "No Essential Contacts configured",
+				Description:    "This project appears to have no Essential Contacts, which can delay incident response.",
+				Resource:       s.opts.Project,
+				Recommendation: "Add security and operations contacts in Google Cloud Essential Contacts.",
+				Metadata: map[string]string{
+					"missing_categories": strings.Join(categories, ","),
+				},
+			},
+		}, nil
 	}
 
-	return []model.Finding{
-		{
-			ID:             "gcp.essential_contacts.none",
-			Check:          "Incident Readiness",
-			Severity:       model.SeverityMedium,
-			Summary:        "No Essential Contacts configured",
-			Description:    "This project appears to have no Essential Contacts, which can delay incident response.",
+	findings := make([]model.Finding, 0)
+	for _, req := range requiredContactCategories {
+		computed, err := s.src.contacts(ctx, s.opts.Project, req.category)
+		if err != nil {
+			return nil, err
+		}
+		if len(computed) > 0 {
+			continue
+		}
+		findings = append(findings, model.Finding{
+			ID:       "gcp.essential_contacts.category_missing",
+			Check:    "Incident Readiness",
+			Severity: req.severity,
+			Summary:  "Essential Contacts do not cover a required notification category",
+			Description: fmt.Sprintf(
+				"No contact on `%s` or its folders/organization receives `%s` notifications.",
+				s.opts.Project,
+				req.category,
+			),
 			Resource:       s.opts.Project,
-			Recommendation: "Add security and operations contacts in Google Cloud Essential Contacts.",
-		},
-	}, nil
+			Recommendation: fmt.Sprintf("Subscribe a monitored group address to the `%s` (or `ALL`) category.", req.category),
+			Metadata: map[string]string{
+				"category": req.category,
+			},
+		})
+	}
+
+	if len(s.opts.AllowedContactDomains) == 0 {
+		return findings, nil
+	}
+
+	contacts := map[string]gcpapi.Contact{}
+	for _, c := range all {
+		contacts[c.Name] = c
+	}
+	names := make([]string, 0, len(contacts))
+	for name := range contacts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		email := contacts[name].Email
+		if emailDomainAllowed(email, s.opts.AllowedContactDomains) {
+			continue
+		}
+		findings = append(findings, model.Finding{
+			ID:       "gcp.essential_contacts.unapproved_domain",
+			Check:    "Incident Readiness",
+			Severity: model.SeverityMedium,
+			Summary:  "Essential Contact uses a domain outside the allowed list",
+			Description: fmt.Sprintf(
+				"Contact `%s` (defined on `%s`) is not in an allowed domain (%s); notifications may leave the organization.",
+				email,
+				contactSource(name),
+				strings.Join(s.opts.AllowedContactDomains, ", "),
+			),
+			Resource:       name,
+			Recommendation: "Replace the contact with an address in an approved corporate domain.",
+			Metadata: map[string]string{
+				"email":  email,
+				"source": contactSource(name),
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+func emailDomainAllowed(email string, allowed []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range allowed {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func contactSource(name string) string {
+	if i := strings.Index(name, "/contacts/"); i >= 0 {
+		return name[:i]
+	}
+	return name
 }
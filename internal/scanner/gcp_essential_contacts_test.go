@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestScanEssentialContactsReportsMissingCategoriesForBillingOnlyProject(t *testing.T) {
+	billing := `[{"name": "projects/demo/contacts/7", "email": "invoices@vendor.example", "notificationCategorySubscriptions": ["BILLING"]}]`
+	runner := stubRunner{
+		"gcloud essential-contacts compute --project demo --notification-categories ALL --format=json":       billing,
+		"gcloud essential-contacts compute --project demo --notification-categories SECURITY --format=json":  `[]`,
+		"gcloud essential-contacts compute --project demo --notification-categories TECHNICAL --format=json": `[]`,
+	}
+	s := New(Options{Project: "demo", Runner: runner, AllowedContactDomains: []string{"example.com"}})
+
+	findings, err := s.scanEssentialContacts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.ID+" "+f.Metadata["category"]+f.Metadata["email"])
+	}
+	want := []string{
+		"gcp.essential_contacts.category_missing SECURITY",
+		"gcp.essential_contacts.category_missing TECHNICAL",
+		"gcp.essential_contacts.unapproved_domain invoices@vendor.example",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected findings:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestScanEssentialContactsWithoutAnyContact(t *testing.T) {
+	runner := stubRunner{
+		"gcloud essential-contacts compute --project demo --notification-categories ALL --format=json": `[]`,
+	}
+	findings, err := New(Options{Project: "demo", Runner: runner}).scanEssentialContacts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].ID != "gcp.essential_contacts.none" {
+		t.Fatalf("expected a single none finding, got %+v", findings)
+	}
+}
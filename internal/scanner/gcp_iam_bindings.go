@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "org-iam",
+			Title:       "IAM Bindings",
+			Category:    "IAM",
+			Description: "Flags public members and primitive roles in the organization IAM policy.",
+			Scopes:      []Scope{ScopeOrganization},
+			FindingIDs:  []string{"gcp.iam.public_member", "gcp.iam.primitive_role"},
+			Permissions: []string{"resourcemanager.organizations.getIamPolicy"},
+			Offline:     true,
+		},
+		run: func(s *Scanner, ctx context.Context) ([]model.Finding, error) {
+			return s.scanOrganizationIAM(ctx, s.scanRoot())
+		},
+	})
+}
+
+var publicMembers = map[string]struct{}{
+	"allUsers":              {},
+	"allAuthenticatedUsers": {},
+}
+
+func iamBindingFindings(resource string, policy map[string]any) []model.Finding {
+	roles := rolesByMember(policy)
+	members := make([]string, 0, len(roles))
+	for m := range roles {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+
+	findings := make([]model.Finding, 0)
+	for _, member := range members {
+		if _, public := publicMembers[member]; public {
+			findings = append(findings, model.Finding{
+				ID:       "gcp.iam.public_member",
+				Check:    "IAM Bindings",
+				Severity: model.SeverityCritical,
+				Summary:  "IAM policy grants access to the public",
+				Description: fmt.Sprintf(
+					"`%s` grants %s to `%s`.",
+					resource,
+					strings.Join(roles[member], ", "),
+					member,
+				),
+				Resource:       resource,
+				Recommendation: "Remove allUsers/allAuthenticatedUsers from the policy and grant access to specific principals.",
+				Metadata: map[string]string{
+					"member": member,
+					"roles":  strings.Join(roles[member], ","),
+				},
+			})
+			continue
+		}
+
+		broad := broadRoles(roles[member])
+		if len(broad) == 0 || strings.HasPrefix(member, "deleted:") {
+			continue
+		}
+		findings = append(findings, model.Finding{
+			ID:       "gcp.iam.primitive_role",
+			Check:    "IAM Bindings",
+			Severity: model.SeverityHigh,
+			Summary:  "Principal holds a primitive role",
+			Description: fmt.Sprintf(
+				"`%s` grants %s to `%s`; primitive roles include permissions to create and use credentials.",
+				resource,
+				strings.Join(broad, ", "),
+				member,
+			),
+			Resource:       resource,
+			Recommendation: "Replace Owner/Editor with predefined roles scoped to what the principal actually does.",
+			Metadata: map[string]string{
+				"member": member,
+				"roles":  strings.Join(broad, ","),
+			},
+		})
+	}
+	return findings
+}
@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+const iamPolicyRecommender = "google.iam.policy.Recommender"
+
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "iam-recommender",
+			Title:       "IAM Recommender",
+			Category:    "IAM",
+			Description: "Turns active IAM Recommender role recommendations into findings.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs:  []string{"gcp.iam_recommender.excess_permissions"},
+			Permissions: []string{"recommender.iamPolicyRecommendations.list"},
+			GCloudOnly:  true,
+		},
+		run: (*Scanner).scanIAMRecommendations,
+	})
+}
+
+func (s *Scanner) scanIAMRecommendations(ctx context.Context) ([]model.Finding, error) {
+	recs, err := s.gcloudJSON(
+		ctx,
+		"recommender", "recommendations", "list",
+		"--project", s.opts.Project,
+		"--location", "global",
+		"--recommender", iamPolicyRecommender,
+	)
+	if err != nil {
+		if isAPIDisabled(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	evaluated(ctx, len(recs))
+	findings := make([]model.Finding, 0)
+	for _, rec := range recs {
+		if state := asString(asMap(rec["stateInfo"])["state"]); state != "" && state != "ACTIVE" {
+			continue
+		}
+
+		change := parseIAMRecommendation(rec)
+		if change.member == "" || len(change.remove) == 0 {
+			continue
+		}
+
+		name := asString(rec["name"])
+		action := fmt.Sprintf("remove %s", strings.Join(change.remove, ", "))
+		if len(change.add) > 0 {
+			action = fmt.Sprintf("replace %s with %s", strings.Join(change.remove, ", "), strings.Join(change.add, ", "))
+		}
+
+		findings = append(findings, model.Finding{
+			ID:       "gcp.iam_recommender.excess_permissions",
+			Check:    "IAM Recommender",
+			Severity: recommenderSeverity(asString(rec["priority"])),
+			Summary:  "Principal holds permissions it does not use",
+			Description: fmt.Sprintf(
+				"IAM Recommender suggests to %s for `%s`: %s",
+				action,
+				change.member,
+				asString(rec["description"]),
+			),
+			Resource:       change.member,
+			Recommendation: "Review the suggested role change and apply it with `gcpsec enforce --iam-recommendations --apply`.",
+			Metadata: map[string]string{
+				"recommendation": name,
+				"etag":           asString(rec["etag"]),
+				"priority":       asString(rec["priority"]),
+				"member":         change.member,
+				"remove_roles":   strings.Join(change.remove, ","),
+				"add_roles":      strings.Join(change.add, ","),
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+type iamRoleChange struct {
+	member string
+	remove []string
+	add    []string
+}
+
+func parseIAMRecommendation(rec map[string]any) iamRoleChange {
+	var change iamRoleChange
+	for _, g := range asSlice(asMap(rec["content"])["operationGroups"]) {
+		for _, item := range asSlice(asMap(g)["operations"]) {
+			op := asMap(item)
+			if asString(op["resourceType"]) != "cloudresourcemanager.googleapis.com/Project" {
+				continue
+			}
+			filters := asMap(op["pathFilters"])
+			role := asString(filters["/iamPolicy/bindings/*/role"])
+			if role == "" {
+				continue
+			}
+			switch asString(op["action"]) {
+			case "add":
+				change.add = append(change.add, role)
+				if change.member == "" {
+					change.member = asString(op["value"])
+				}
+			case "remove":
+				change.remove = append(change.remove, role)
+				if m := asString(filters["/iamPolicy/bindings/*/members/*"]); m != "" {
+					change.member = m
+				}
+			}
+		}
+	}
+	sort.Strings(change.remove)
+	sort.Strings(change.add)
+	return change
+}
+
+func recommenderSeverity(priority string) model.Severity {
+	switch priority {
+	case "P1":
+		return model.SeverityHigh
+	case "P2":
+		return model.SeverityMedium
+	case "P3":
+		return model.SeverityLow
+	default:
+		return model.SeverityInfo
+	}
+}
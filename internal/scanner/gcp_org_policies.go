@@ -2,78 +2,205 @@ package scanner
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 )
 
+const (
+	orgPolicyNotSet      = "not_set"
+	orgPolicyOverridden  = "overridden"
+	orgPolicyNotEnforced = "not_enforced"
+)
+
+type resourceRef struct {
+	kind string
+	id   string
+}
+
+func (r resourceRef) name() string {
+	switch r.kind {
+	case "organization":
+		return "organizations/" + r.id
+	case "folder":
+		return "folders/" + r.id
+	default:
+		return "projects/" + r.id
+	}
+}
+
+func (r resourceRef) flag() []string {
+	return []string{"--" + r.kind, r.id}
+}
+
+func policyName(ref resourceRef, constraint string) string {
+	return ref.name() + "/policies/" + strings.TrimPrefix(constraint, "constraints/")
+}
+
+func init() {
+	var ids []string
+	for _, rule := range DefaultOrgPolicyBaseline() {
+		ids = append(ids, withOrgPolicyDefaults(rule).ID)
+	}
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "org-policies",
+			Title:       "Organization Policy",
+			Category:    "Organization Policy",
+			Description: "Evaluates the effective organization policy against the configured constraint baseline.",
+			Scopes:      []Scope{ScopeProject, ScopeFolder, ScopeOrganization},
+			FindingIDs:  ids,
+			Permissions: []string{"orgpolicy.policy.get", "resourcemanager.projects.get"},
+			Offline:     true,
+		},
+		run: func(s *Scanner, ctx context.Context) ([]model.Finding, error) {
+			if s.opts.Project == "" {
+				return s.scanRootOrgPolicies(ctx, s.scanRoot())
+			}
+			return s.scanOrgPolicies(ctx)
+		},
+		ids: func(s *Scanner) []string {
+			ids := make([]string, 0, len(s.orgPolicies))
+			for _, rule := range s.orgPolicies {
+				ids = append(ids, rule.ID)
+			}
+			return ids
+		},
+	})
+}
+
 func (s *Scanner) scanOrgPolicies(ctx context.Context) ([]model.Finding, error) {
-	findings := make([]model.Finding, 0, 2)
-
-	expiryPolicyList, err := s.gcloudJSON(
-		ctx,
-		"resource-manager", "org-policies", "describe",
-		"constraints/iam.serviceAccountKeyExpiryHours",
-		"--project", s.opts.Project,
-	)
+	findings := make([]model.Finding, 0, len(s.orgPolicies))
+	project := resourceRef{kind: "project", id: s.opts.Project}
+
+	var ancestors []resourceRef
+	ancestorsKnown := true
+	var partial partialError
+	for _, rule := range s.orgPolicies {
+		effective, err := s.src.orgPolicy(ctx, rule.Constraint, project, true)
+		if err != nil {
+			partial.add(policyName(project, rule.Constraint), err)
+			continue
+		}
+		evaluated(ctx, 1)
+		if ok, _ := evaluateOrgPolicy(rule, effective); ok {
+			continue
+		}
+
+		if ancestors == nil {
+			ancestors, err = s.projectAncestors(ctx)
+			if err != nil {
+				partial.add(project.name(), fmt.Errorf("cannot read ancestors: %w", err))
+				ancestors, ancestorsKnown = []resourceRef{project}, false
+			}
+		}
+
+		status, source, reason, err := s.orgPolicySource(ctx, rule, ancestors)
+		if err != nil {
+			partial.add(policyName(project, rule.Constraint), err)
+			continue
+		}
+		if status == orgPolicyNotSet && !ancestorsKnown {
+			// Only the project was inspected; the policy may well be set
+			// above it, so say what is known instead of not_set.
+			status, reason = orgPolicyNotEnforced, "is not compliant in the effective policy; the folders and organization above the project could not be read"
+		}
+		findings = append(findings, effectiveOrgPolicyFinding(rule, s.opts.Project, status, source, reason))
+	}
+
+	return findings, partial.err()
+}
+
+func (s *Scanner) projectAncestors(ctx context.Context) ([]resourceRef, error) {
+	out, err := s.src.ancestors(ctx, s.opts.Project)
 	if err != nil {
 		return nil, err
 	}
-	if len(expiryPolicyList) > 0 && !policyHasRules(expiryPolicyList[0]) {
-		findings = append(findings, model.Finding{
-			ID:             "gcp.org_policy.key_expiry_missing",
-			Check:          "Mandatory Rotation",
-			Severity:       model.SeverityHigh,
-			Summary:        "Service account key max lifetime policy is not configured",
-			Description:    "`constraints/iam.serviceAccountKeyExpiryHours` does not appear to have active rules.",
-			Resource:       s.opts.Project,
-			Recommendation: "Set an enforced maximum lifetime for user-managed service account keys.",
-		})
+	if len(out) == 0 {
+		out = append(out, resourceRef{kind: "project", id: s.opts.Project})
 	}
+	return out, nil
+}
 
-	disableCreationPolicyList, err := s.gcloudJSON(
-		ctx,
-		"resource-manager", "org-policies", "describe",
-		"constraints/iam.managed.disableServiceAccountKeyCreation",
-		"--project", s.opts.Project,
-	)
-	if err != nil {
-		return nil, err
+// orgPolicySource walks the hierarchy from the project upwards to find the
+// nearest resource that sets the constraint, and whether a project-level
+// policy overrides a compliant one set by its parent.
+func (s *Scanner) orgPolicySource(ctx context.Context, rule model.OrgPolicyRule, ancestors []resourceRef) (string, string, string, error) {
+	var source, reason string
+	for i, ref := range ancestors {
+		policy, err := s.src.orgPolicy(ctx, rule.Constraint, ref, false)
+		if err != nil {
+			return "", "", "", err
+		}
+		if !policy.IsSet() {
+			continue
+		}
+
+		ok, why := evaluateOrgPolicy(rule, policy)
+		if source == "" {
+			source, reason = ref.name(), why
+			if reason == "" {
+				reason = "is not compliant once inherited rules are merged"
+			}
+			if i == 0 && ref.kind == "project" {
+				continue
+			}
+			break
+		}
+		if ok {
+			return orgPolicyOverridden, source, fmt.Sprintf("is enforced at `%s` but the project-level policy overrides it", ref.name()), nil
+		}
+		break
 	}
-	if len(disableCreationPolicyList) > 0 && !policyEnforcesBoolean(disableCreationPolicyList[0]) {
-		findings = append(findings, model.Finding{
-			ID:             "gcp.org_policy.disable_key_creation_missing",
-			Check:          "Mandatory Rotation",
-			Severity:       model.SeverityMedium,
-			Summary:        "Service account key creation is not disabled by policy",
-			Description:    "`constraints/iam.managed.disableServiceAccountKeyCreation` is not enforced.",
-			Resource:       s.opts.Project,
-			Recommendation: "If key-based auth is not required, enforce policy to disable new user-managed service account keys.",
-		})
+
+	if source == "" {
+		return orgPolicyNotSet, "", "is not set on the project or on any folder or organization above it", nil
 	}
+	return orgPolicyNotEnforced, source, reason, nil
+}
 
-	return findings, nil
+func effectiveOrgPolicyFinding(rule model.OrgPolicyRule, project, status, source, reason string) model.Finding {
+	f := orgPolicyFinding(rule, project, reason)
+	if source != "" && status == orgPolicyNotEnforced {
+		f.Description = fmt.Sprintf("`%s` %s (effective policy set at `%s`).", rule.Constraint, reason, source)
+	}
+	f.Metadata["status"] = status
+	if source != "" {
+		f.Metadata["policy_source"] = source
+	}
+	return f
 }
 
-func policyHasRules(policy map[string]any) bool {
-	spec := asMap(policy["spec"])
-	if len(spec) == 0 {
-		return false
+func orgPolicyFinding(rule model.OrgPolicyRule, resource, reason string) model.Finding {
+	return model.Finding{
+		ID:             rule.ID,
+		Check:          rule.Check,
+		Severity:       rule.Severity,
+		Summary:        rule.Summary,
+		Description:    fmt.Sprintf("`%s` %s.", rule.Constraint, reason),
+		Resource:       resource,
+		Recommendation: rule.Recommendation,
+		Metadata: map[string]string{
+			"constraint": rule.Constraint,
+		},
 	}
-	rules := asSlice(spec["rules"])
-	return len(rules) > 0
 }
 
-func policyEnforcesBoolean(policy map[string]any) bool {
-	spec := asMap(policy["spec"])
-	rules := asSlice(spec["rules"])
-	for _, item := range rules {
-		rule := asMap(item)
-		if asBool(rule["enforce"]) {
-			return true
-		}
-		if bp := asMap(rule["booleanPolicy"]); asBool(bp["enforced"]) {
-			return true
+// policyEnforcesBoolean reports whether a boolean constraint is enforced
+// for every resource. A rule with a condition only applies to the resources
+// it matches, so a conditional enforce does not count and a conditional
+// exception (enforce false) means the constraint is not fully enforced.
+func policyEnforcesBoolean(policy gcpapi.OrgPolicy) bool {
+	enforced := false
+	for _, rule := range policy.Rules() {
+		switch {
+		case rule.Condition != nil && !rule.Enforce:
+			return false
+		case rule.Condition == nil && rule.Enforce:
+			enforced = true
 		}
 	}
-	return false
+	return enforced
 }
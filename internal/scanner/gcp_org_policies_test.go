@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type stubRunner map[string]string
+
+func (r stubRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	key := strings.Join(append([]string{name}, args...), " ")
+	out, ok := r[key]
+	if !ok {
+		return nil, errors.New(key + " failed: NOT_FOUND")
+	}
+	return []byte(out), nil
+}
+
+func (stubRunner) LookPath(file string) (string, error) { return "/usr/bin/" + file, nil }
+
+type deniedRunner struct {
+	stubRunner
+	denied string
+}
+
+func (r deniedRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if key := strings.Join(append([]string{name}, args...), " "); key == r.denied {
+		return nil, errors.New(key + " failed: PERMISSION_DENIED")
+	}
+	return r.stubRunner.Run(ctx, name, args...)
+}
+
+func TestScanOrgPoliciesDetectsProjectOverride(t *testing.T) {
+	const constraint = "constraints/compute.requireOsLogin"
+	runner := stubRunner{
+		"gcloud org-policies describe " + constraint + " --project demo --effective --format=json": `{"spec": {"rules": [{"enforce": false}]}}`,
+		"gcloud projects get-ancestors demo --format=json":                                         `[{"id": "demo", "type": "project"}, {"id": "42", "type": "organization"}]`,
+		"gcloud org-policies describe " + constraint + " --project demo --format=json":             `{"spec": {"rules": [{"enforce": false}]}}`,
+		"gcloud org-policies describe " + constraint + " --organization 42 --format=json":          `{"spec": {"rules": [{"enforce": true}]}}`,
+	}
+
+	rule := withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: constraint, Enforced: boolPtr(true)})
+	s := New(Options{Project: "demo", Runner: runner, OrgPolicyBaseline: []model.OrgPolicyRule{rule}})
+
+	findings, err := s.scanOrgPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("expected one finding, got %d", len(findings))
+	}
+	if got := findings[0].Metadata["status"]; got != orgPolicyOverridden {
+		t.Fatalf("expected status %s, got %s", orgPolicyOverridden, got)
+	}
+	if got := findings[0].Metadata["policy_source"]; got != "projects/demo" {
+		t.Fatalf("expected policy source projects/demo, got %s", got)
+	}
+}
+
+func TestScanOrgPoliciesAcceptsInheritedEnforcement(t *testing.T) {
+	const constraint = "constraints/compute.requireOsLogin"
+	runner := stubRunner{
+		"gcloud org-policies describe " + constraint + " --project demo --effective --format=json": `{"spec": {"rules": [{"enforce": true}]}}`,
+	}
+
+	rule := withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: constraint, Enforced: boolPtr(true)})
+	s := New(Options{Project: "demo", Runner: runner, OrgPolicyBaseline: []model.OrgPolicyRule{rule}})
+
+	findings, err := s.scanOrgPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("expected inherited enforcement to pass, got %+v", findings)
+	}
+}
+
+func TestScanOrgPoliciesKeepsFindingsWhenOneConstraintFails(t *testing.T) {
+	const constraint = "constraints/compute.requireOsLogin"
+	runner := deniedRunner{
+		stubRunner: stubRunner{
+			"gcloud org-policies describe " + constraint + " --project demo --effective --format=json": `{}`,
+			"gcloud projects get-ancestors demo --format=json":                                         `[{"id": "demo", "type": "project"}]`,
+			"gcloud org-policies describe " + constraint + " --project demo --format=json":             `{}`,
+		},
+		denied: "gcloud org-policies describe constraints/iam.disableServiceAccountKeyUpload --project demo --effective --format=json",
+	}
+
+	baseline := []model.OrgPolicyRule{
+		withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/iam.disableServiceAccountKeyUpload", Enforced: boolPtr(true)}),
+		withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: constraint, Enforced: boolPtr(true)}),
+	}
+	s := New(Options{Project: "demo", Runner: runner, OrgPolicyBaseline: baseline})
+
+	findings, err := s.scanOrgPolicies(context.Background())
+	var partial *partialError
+	if !errors.As(err, &partial) || len(partial.errors) != 1 {
+		t.Fatalf("expected one resource error, got %v", err)
+	}
+	if got := partial.errors[0].Resource; got != "projects/demo/policies/iam.disableServiceAccountKeyUpload" {
+		t.Fatalf("unexpected resource %s", got)
+	}
+	if len(findings) != 1 || findings[0].Metadata["constraint"] != constraint {
+		t.Fatalf("expected the remaining constraint to be reported, got %+v", findings)
+	}
+}
+
+func TestScanOrgPoliciesDoesNotClaimNotSetWithoutAncestors(t *testing.T) {
+	const constraint = "constraints/compute.requireOsLogin"
+	runner := deniedRunner{
+		stubRunner: stubRunner{
+			"gcloud org-policies describe " + constraint + " --project demo --effective --format=json": `{}`,
+		},
+		denied: "gcloud projects get-ancestors demo --format=json",
+	}
+	rule := withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: constraint, Enforced: boolPtr(true)})
+	s := New(Options{Project: "demo", Runner: runner, OrgPolicyBaseline: []model.OrgPolicyRule{rule}})
+
+	findings, err := s.scanOrgPolicies(context.Background())
+	var partial *partialError
+	if !errors.As(err, &partial) || len(partial.errors) != 1 || partial.errors[0].Resource != "projects/demo" {
+		t.Fatalf("expected a resource error for the ancestors, got %v", err)
+	}
+	if len(findings) != 1 || findings[0].Metadata["status"] == orgPolicyNotSet {
+		t.Fatalf("expected a finding that does not claim not_set, got %+v", findings)
+	}
+}
+
+func TestConditionalEnforcementIsNotFullEnforcement(t *testing.T) {
+	conditional := gcpapi.OrgPolicy{Spec: &gcpapi.PolicySpec{Rules: []gcpapi.PolicyRule{
+		{Enforce: true, Condition: &gcpapi.Expr{Expression: `resource.matchTag("123/env", "prod")`}},
+	}}}
+	exception := gcpapi.OrgPolicy{Spec: &gcpapi.PolicySpec{Rules: []gcpapi.PolicyRule{
+		{Enforce: false, Condition: &gcpapi.Expr{Expression: `resource.matchTag("123/env", "dev")`}},
+		{Enforce: true},
+	}}}
+	unconditional := gcpapi.OrgPolicy{Spec: &gcpapi.PolicySpec{Rules: []gcpapi.PolicyRule{{Enforce: true}}}}
+
+	if policyEnforcesBoolean(conditional) || policyEnforcesBoolean(exception) {
+		t.Fatal("expected conditional rules not to count as full enforcement")
+	}
+	if !policyEnforcesBoolean(unconditional) {
+		t.Fatal("expected an unconditional rule to enforce the constraint")
+	}
+}
+
+func TestOrgPolicyExecutionRecordsConfiguredFindingIDs(t *testing.T) {
+	rule := withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: "constraints/custom.requireLabels", Enforced: boolPtr(true)})
+	s := New(Options{Project: "demo", Runner: stubRunner{}, Checks: []string{"org-policies"}, OrgPolicyBaseline: []model.OrgPolicyRule{rule}})
+
+	result, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Executions) != 1 || !reflect.DeepEqual(result.Executions[0].FindingIDs, []string{"gcp.org_policy.custom.requireLabels"}) {
+		t.Fatalf("expected the configured rule id on the execution, got %+v", result.Executions)
+	}
+}
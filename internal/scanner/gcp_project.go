@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+func (s *Scanner) projectNumber(ctx context.Context) (string, error) {
+	list, err := s.gcloudJSON(ctx, "projects", "describe", s.opts.Project)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return "", fmt.Errorf("project %s not found", s.opts.Project)
+	}
+	number := asString(list[0]["projectNumber"])
+	if number == "" {
+		return "", fmt.Errorf("project %s has no projectNumber", s.opts.Project)
+	}
+	return number, nil
+}
+
+func (s *Scanner) projectIAMPolicy(ctx context.Context) (map[string]any, error) {
+	return s.src.iamPolicy(ctx, resourceRef{kind: "project", id: s.opts.Project})
+}
+
+func rolesByMember(policy map[string]any) map[string][]string {
+	out := map[string][]string{}
+	for _, item := range asSlice(policy["bindings"]) {
+		binding := asMap(item)
+		role := asString(binding["role"])
+		if role == "" {
+			continue
+		}
+		for _, m := range asSlice(binding["members"]) {
+			member := asString(m)
+			if member == "" {
+				continue
+			}
+			out[member] = append(out[member], role)
+		}
+	}
+	for member := range out {
+		sort.Strings(out[member])
+	}
+	return out
+}
@@ -5,11 +5,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
 )
 
+func init() {
+	Register(checkFunc{
+		info: CheckInfo{
+			ID:          "service-account-keys",
+			Title:       "Service Account Keys",
+			Category:    "Credentials",
+			Description: "Flags user-managed service account keys without expiry or older than --inactive-days.",
+			Scopes:      []Scope{ScopeProject},
+			FindingIDs:  []string{"gcp.sa_key.no_expiry", "gcp.sa_key.stale_review"},
+			Permissions: []string{"iam.serviceAccounts.list", "iam.serviceAccountKeys.list"},
+			Offline:     true,
+		},
+		run: (*Scanner).scanServiceAccountKeys,
+	})
+}
+
 func (s *Scanner) scanServiceAccountKeys(ctx context.Context) ([]model.Finding, error) {
-	accounts, err := s.gcloudJSON(ctx, "iam", "service-accounts", "list", "--project", s.opts.Project)
+	accounts, err := s.src.serviceAccounts(ctx, s.opts.Project)
 	if err != nil {
 		return nil, err
 	}
@@ -17,31 +34,38 @@ func (s *Scanner) scanServiceAccountKeys(ctx context.Context) ([]model.Finding,
 	findings := make([]model.Finding, 0)
 	now := time.Now().UTC()
 
-	for _, account := range accounts {
-		email := asString(account["email"])
+	keysByAccount := make([][]gcpapi.ServiceAccountKey, len(accounts))
+	keyErrs := make([]error, len(accounts))
+	forEach(s.opts.Concurrency, len(accounts), func(i int) {
+		if accounts[i].Email == "" {
+			return
+		}
+		keysByAccount[i], keyErrs[i] = s.src.serviceAccountKeys(ctx, s.opts.Project, accounts[i].Email)
+	})
+	var partial partialError
+	for i, account := range accounts {
+		email := account.Email
 		if email == "" {
 			continue
 		}
-
-		keys, keyErr := s.gcloudJSON(
-			ctx,
-			"iam", "service-accounts", "keys", "list",
-			"--iam-account", email,
-			"--managed-by", "user",
-			"--project", s.opts.Project,
-		)
-		if keyErr != nil {
-			return nil, keyErr
+		if keyErrs[i] != nil {
+			resource := account.Name
+			if resource == "" {
+				resource = fmt.Sprintf("projects/%s/serviceAccounts/%s", s.opts.Project, email)
+			}
+			partial.add(resource, keyErrs[i])
+			continue
 		}
+		evaluated(ctx, len(keysByAccount[i]))
 
-		for _, key := range keys {
-			if asBool(key["disabled"]) {
+		for _, key := range keysByAccount[i] {
+			if key.Disabled {
 				continue
 			}
 
-			keyName := asString(key["name"])
-			createdAt := asString(key["validAfterTime"])
-			expiresAt := asString(key["validBeforeTime"])
+			keyName := key.Name
+			createdAt := key.ValidAfterTime
+			expiresAt := key.ValidBeforeTime
 
 			if expiresAt == "" {
 				findings = append(findings, model.Finding{
@@ -92,5 +116,5 @@ func (s *Scanner) scanServiceAccountKeys(ctx context.Context) ([]model.Finding,
 		}
 	}
 
-	return findings, nil
+	return findings, partial.err()
 }
@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func (s *Scanner) scanRoot() resourceRef {
+	if s.opts.Organization != "" {
+		return resourceRef{kind: "organization", id: s.opts.Organization}
+	}
+	return resourceRef{kind: "folder", id: s.opts.Folder}
+}
+
+func (s *Scanner) scanHierarchy(ctx context.Context, result *model.ScanResult) {
+	root := s.scanRoot()
+	result.Project = ""
+	result.Organization = s.opts.Organization
+	result.Folder = s.opts.Folder
+
+	s.runChecks(ctx, result, s.checksFor(Scope(root.kind)))
+
+	projects, err := s.listDescendantProjects(ctx, root)
+	if err != nil {
+		var partial *partialError
+		if errors.As(err, &partial) {
+			for _, e := range partial.errors {
+				e.Check = "project-discovery"
+				result.Errors = append(result.Errors, e)
+			}
+		}
+		result.Notes = append(result.Notes, fmt.Sprintf("listing projects under %s is incomplete: %v", root.name(), err))
+	}
+
+	selection := s.opts.Selection
+	if len(selection.States) == 0 {
+		selection.States = []string{"ACTIVE"}
+	}
+	result.Selection = &selection
+
+	active := make([]model.Project, 0, len(projects))
+	for _, p := range projects {
+		if selection.Match(p) {
+			active = append(active, p)
+		}
+	}
+
+	s.scanProjects(ctx, result, active)
+}
+
+func (s *Scanner) scanProjects(ctx context.Context, result *model.ScanResult, projects []model.Project) {
+	scans := make([]model.ScanResult, len(projects))
+	forEach(s.opts.Parallelism, len(projects), func(i int) {
+		child := s.forProject(projects[i].ID)
+		child.runProjectChecks(ctx, &scans[i], child.checksFor(ScopeProject))
+	})
+
+	for i, p := range projects {
+		result.Projects = append(result.Projects, p)
+		result.Findings = append(result.Findings, scans[i].Findings...)
+		result.Errors = append(result.Errors, scans[i].Errors...)
+		result.Executions = append(result.Executions, scans[i].Executions...)
+		for _, note := range scans[i].Notes {
+			result.Notes = append(result.Notes, fmt.Sprintf("project %s: %s", p.ID, note))
+		}
+	}
+}
+
+func (s *Scanner) scanRootOrgPolicies(ctx context.Context, root resourceRef) ([]model.Finding, error) {
+	findings := make([]model.Finding, 0)
+	var partial partialError
+	for _, rule := range s.orgPolicies {
+		policy, err := s.src.orgPolicy(ctx, rule.Constraint, root, true)
+		if err != nil {
+			partial.add(policyName(root, rule.Constraint), err)
+			continue
+		}
+		evaluated(ctx, 1)
+		ok, reason := evaluateOrgPolicy(rule, policy)
+		if ok {
+			continue
+		}
+		f := orgPolicyFinding(rule, root.name(), reason)
+		f.Metadata["status"] = orgPolicyNotEnforced
+		if !policy.IsSet() {
+			f.Metadata["status"] = orgPolicyNotSet
+		}
+		findings = append(findings, f)
+	}
+	return findings, partial.err()
+}
+
+func (s *Scanner) scanOrganizationIAM(ctx context.Context, root resourceRef) ([]model.Finding, error) {
+	policy, err := s.src.iamPolicy(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	evaluated(ctx, len(asSlice(policy["bindings"])))
+	return iamBindingFindings(root.name(), policy), nil
+}
+
+// listDescendantProjects walks the folders under root breadth first. A
+// parent whose projects or folders cannot be listed is recorded and the walk
+// goes on with the rest of the tree.
+func (s *Scanner) listDescendantProjects(ctx context.Context, root resourceRef) ([]model.Project, error) {
+	var out []model.Project
+	var partial partialError
+	queue := []resourceRef{root}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+
+		projects, err := s.src.childProjects(ctx, parent)
+		if err != nil {
+			partial.add(parent.name(), fmt.Errorf("cannot list projects: %w", err))
+		}
+		out = append(out, projects...)
+
+		folders, err := s.src.childFolders(ctx, parent)
+		if err != nil {
+			partial.add(parent.name(), fmt.Errorf("cannot list folders: %w", err))
+		}
+		queue = append(queue, folders...)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, partial.err()
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestScanOrganizationWalksFoldersAndTagsFindings(t *testing.T) {
+	runner := stubRunner{
+		"gcloud organizations get-iam-policy 42 --format=json":                                  `{"bindings": [{"role": "roles/viewer", "members": ["allUsers"]}]}`,
+		"gcloud projects list --filter parent.type=organization AND parent.id=42 --format=json": `[{"projectId": "app-prod", "lifecycleState": "ACTIVE"}]`,
+		"gcloud resource-manager folders list --organization 42 --format=json":                  `[{"name": "folders/7"}]`,
+		"gcloud projects list --filter parent.type=folder AND parent.id=7 --format=json":        `[{"projectId": "app-dev", "lifecycleState": "ACTIVE"}, {"projectId": "old", "lifecycleState": "DELETE_REQUESTED"}]`,
+		"gcloud resource-manager folders list --folder 7 --format=json":                         `[]`,
+		"gcloud services api-keys list --project app-prod --format=json":                        `[{"name": "projects/1/locations/global/keys/k1", "displayName": "web"}]`,
+	}
+
+	s := New(Options{Organization: "42", Runner: runner, OrgPolicyBaseline: []model.OrgPolicyRule{}})
+	result, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	var ids []string
+	for _, p := range result.Projects {
+		ids = append(ids, p.ID)
+	}
+	if strings.Join(ids, ",") != "app-dev,app-prod" {
+		t.Fatalf("expected active descendant projects, got %v", result.Projects)
+	}
+
+	var orgIAM, apiKey bool
+	for _, f := range result.Findings {
+		switch f.ID {
+		case "gcp.iam.public_member":
+			orgIAM = f.Resource == "organizations/42" && f.Project == ""
+		case "gcp.api_key.unrestricted":
+			apiKey = f.Project == "app-prod"
+		}
+	}
+	if !orgIAM || !apiKey {
+		t.Fatalf("expected org IAM and per-project findings, got %+v", result.Findings)
+	}
+
+	for _, note := range result.Notes {
+		if strings.HasPrefix(note, "project app-dev: api-keys check failed") {
+			return
+		}
+	}
+	t.Fatalf("expected per-project failure note, got %v", result.Notes)
+}
+
+func TestListDescendantProjectsKeepsWalkingPastFailures(t *testing.T) {
+	runner := deniedRunner{
+		stubRunner: stubRunner{
+			"gcloud projects list --filter parent.type=organization AND parent.id=42 --format=json": `[{"projectId": "zeta", "lifecycleState": "ACTIVE"}]`,
+			"gcloud resource-manager folders list --organization 42 --format=json":                  `[{"name": "folders/7"}, {"name": "folders/8"}]`,
+			"gcloud resource-manager folders list --folder 7 --format=json":                         `[]`,
+			"gcloud projects list --filter parent.type=folder AND parent.id=8 --format=json":        `[{"projectId": "alpha", "lifecycleState": "ACTIVE"}]`,
+			"gcloud resource-manager folders list --folder 8 --format=json":                         `[]`,
+		},
+		denied: "gcloud projects list --filter parent.type=folder AND parent.id=7 --format=json",
+	}
+	s := New(Options{Organization: "42", Runner: runner})
+
+	projects, err := s.listDescendantProjects(context.Background(), s.scanRoot())
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	if strings.Join(ids, ",") != "alpha,zeta" {
+		t.Fatalf("expected the walk to continue past folders/7 and sort, got %v", ids)
+	}
+	var partial *partialError
+	if !errors.As(err, &partial) || len(partial.errors) != 1 || partial.errors[0].Resource != "folders/7" {
+		t.Fatalf("expected a resource error for folders/7, got %v", err)
+	}
+}
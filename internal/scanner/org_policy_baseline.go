@@ -0,0 +1,221 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func boolPtr(v bool) *bool { return &v }
+
+func DefaultOrgPolicyBaseline() []model.OrgPolicyRule {
+	return []model.OrgPolicyRule{
+		{
+			Constraint:     "constraints/iam.serviceAccountKeyExpiryHours",
+			ID:             "gcp.org_policy.key_expiry_missing",
+			Check:          "Mandatory Rotation",
+			Severity:       model.SeverityHigh,
+			Summary:        "Service account key max lifetime policy is not configured",
+			Recommendation: "Set an enforced maximum lifetime for user-managed service account keys.",
+		},
+		{
+			Constraint:     "constraints/iam.managed.disableServiceAccountKeyCreation",
+			ID:             "gcp.org_policy.disable_key_creation_missing",
+			Check:          "Mandatory Rotation",
+			Severity:       model.SeverityMedium,
+			Enforced:       boolPtr(true),
+			Summary:        "Service account key creation is not disabled by policy",
+			Recommendation: "If key-based auth is not required, enforce policy to disable new user-managed service account keys.",
+		},
+		{
+			Constraint:     "constraints/iam.disableServiceAccountKeyUpload",
+			Severity:       model.SeverityMedium,
+			Enforced:       boolPtr(true),
+			Summary:        "Service account key upload is not disabled by policy",
+			Recommendation: "Enforce the constraint so externally generated keys cannot be attached to service accounts.",
+		},
+		{
+			Constraint:     "constraints/iam.allowedPolicyMemberDomains",
+			Severity:       model.SeverityHigh,
+			Summary:        "IAM policies can grant access to any domain",
+			Recommendation: "Restrict IAM members to your Cloud Identity / Workspace customer IDs (domain restricted sharing).",
+		},
+		{
+			Constraint:     "constraints/iam.automaticIamGrantsForDefaultServiceAccounts",
+			Severity:       model.SeverityMedium,
+			Enforced:       boolPtr(true),
+			Summary:        "Default service accounts still receive the Editor role automatically",
+			Recommendation: "Enforce the constraint so new default service accounts are not granted Editor.",
+		},
+		{
+			Constraint:     "constraints/storage.publicAccessPrevention",
+			Severity:       model.SeverityMedium,
+			Enforced:       boolPtr(true),
+			Summary:        "Public access prevention is not enforced for Cloud Storage",
+			Recommendation: "Enforce public access prevention so buckets and objects cannot be shared with allUsers.",
+		},
+		{
+			Constraint:     "constraints/compute.requireOsLogin",
+			Severity:       model.SeverityLow,
+			Enforced:       boolPtr(true),
+			Summary:        "OS Login is not required for Compute Engine",
+			Recommendation: "Enforce OS Login so SSH access is governed by IAM instead of project-wide SSH keys.",
+		},
+	}
+}
+
+func MergeOrgPolicyBaseline(base, overrides []model.OrgPolicyRule) ([]model.OrgPolicyRule, error) {
+	out := make([]model.OrgPolicyRule, 0, len(base)+len(overrides))
+	for _, r := range base {
+		r.Constraint = normalizeConstraint(r.Constraint)
+		out = append(out, r)
+	}
+
+	for _, o := range overrides {
+		o.Constraint = normalizeConstraint(o.Constraint)
+		if o.Constraint == "constraints/" {
+			return nil, fmt.Errorf("org policy rule is missing constraint")
+		}
+		if o.Severity != "" {
+			severity, err := model.ParseSeverity(string(o.Severity))
+			if err != nil {
+				return nil, fmt.Errorf("org policy rule %s: %w", o.Constraint, err)
+			}
+			o.Severity = severity
+		}
+
+		idx := -1
+		for i := range out {
+			if out[i].Constraint == o.Constraint {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			out = append(out, o)
+			continue
+		}
+		out[idx] = mergeOrgPolicyRule(out[idx], o)
+	}
+
+	active := out[:0]
+	for _, r := range out {
+		if r.Disabled {
+			continue
+		}
+		active = append(active, withOrgPolicyDefaults(r))
+	}
+	return active, nil
+}
+
+func mergeOrgPolicyRule(base, o model.OrgPolicyRule) model.OrgPolicyRule {
+	if o.ID != "" {
+		base.ID = o.ID
+	}
+	if o.Check != "" {
+		base.Check = o.Check
+	}
+	if o.Severity != "" {
+		base.Severity = o.Severity
+	}
+	if o.Enforced != nil {
+		base.Enforced = o.Enforced
+	}
+	if o.AllowedValues != nil {
+		base.AllowedValues = o.AllowedValues
+	}
+	if o.DeniedValues != nil {
+		base.DeniedValues = o.DeniedValues
+	}
+	if o.Summary != "" {
+		base.Summary = o.Summary
+	}
+	if o.Recommendation != "" {
+		base.Recommendation = o.Recommendation
+	}
+	base.Disabled = o.Disabled
+	return base
+}
+
+func withOrgPolicyDefaults(r model.OrgPolicyRule) model.OrgPolicyRule {
+	short := strings.TrimPrefix(r.Constraint, "constraints/")
+	if r.ID == "" {
+		r.ID = "gcp.org_policy." + short
+	}
+	if r.Check == "" {
+		r.Check = "Organization Policy"
+	}
+	if r.Severity == "" {
+		r.Severity = model.SeverityMedium
+	}
+	if r.Summary == "" {
+		r.Summary = fmt.Sprintf("Organization policy `%s` does not match the baseline", short)
+	}
+	if r.Recommendation == "" {
+		r.Recommendation = fmt.Sprintf("Configure `%s` at the organization or project level as the baseline expects.", r.Constraint)
+	}
+	return r
+}
+
+func evaluateOrgPolicy(rule model.OrgPolicyRule, policy gcpapi.OrgPolicy) (bool, string) {
+	rules := policy.Rules()
+
+	if rule.Enforced != nil {
+		if policyEnforcesBoolean(policy) == *rule.Enforced {
+			return true, ""
+		}
+		if *rule.Enforced {
+			return false, "is not enforced"
+		}
+		return false, "is enforced but the baseline expects it off"
+	}
+
+	if len(rules) == 0 {
+		return false, "has no active rules"
+	}
+
+	var allowed, denied []string
+	denyAll := false
+	for _, r := range rules {
+		if r.AllowAll {
+			return false, "allows all values"
+		}
+		if r.DenyAll {
+			denyAll = true
+		}
+		if r.Values != nil {
+			allowed = append(allowed, r.Values.AllowedValues...)
+			denied = append(denied, r.Values.DeniedValues...)
+		}
+	}
+
+	if len(rule.AllowedValues) > 0 && !denyAll {
+		if len(allowed) == 0 {
+			return false, "does not restrict allowed values"
+		}
+		for _, v := range allowed {
+			if !slices.Contains(rule.AllowedValues, v) {
+				return false, fmt.Sprintf("allows `%s`, which is outside the baseline", v)
+			}
+		}
+	}
+	if !denyAll {
+		for _, v := range rule.DeniedValues {
+			if !slices.Contains(denied, v) {
+				return false, fmt.Sprintf("does not deny `%s`", v)
+			}
+		}
+	}
+	return true, ""
+}
+
+func normalizeConstraint(c string) string {
+	c = strings.TrimSpace(c)
+	if strings.HasPrefix(c, "constraints/") {
+		return c
+	}
+	return "constraints/" + c
+}
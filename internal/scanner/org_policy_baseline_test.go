@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestMergeOrgPolicyBaselineOverridesAndDisables(t *testing.T) {
+	overrides := []model.OrgPolicyRule{
+		{Constraint: "compute.requireOsLogin", Severity: model.SeverityHigh},
+		{Constraint: "storage.publicAccessPrevention", Disabled: true},
+		{Constraint: "compute.vmExternalIpAccess", DeniedValues: []string{"*"}},
+	}
+
+	rules, err := MergeOrgPolicyBaseline(DefaultOrgPolicyBaseline(), overrides)
+	if err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+
+	byConstraint := map[string]model.OrgPolicyRule{}
+	for _, r := range rules {
+		byConstraint[r.Constraint] = r
+	}
+
+	if r := byConstraint["constraints/compute.requireOsLogin"]; r.Severity != model.SeverityHigh || r.Enforced == nil || !*r.Enforced {
+		t.Fatalf("expected severity override to keep enforcement, got %+v", r)
+	}
+	if _, ok := byConstraint["constraints/storage.publicAccessPrevention"]; ok {
+		t.Fatalf("expected disabled rule to be dropped")
+	}
+	added, ok := byConstraint["constraints/compute.vmExternalIpAccess"]
+	if !ok || added.ID != "gcp.org_policy.compute.vmExternalIpAccess" || added.Severity != model.SeverityMedium {
+		t.Fatalf("expected new rule with defaults, got %+v", added)
+	}
+}
+
+func TestEvaluateOrgPolicyListConstraint(t *testing.T) {
+	rule := model.OrgPolicyRule{Constraint: "constraints/iam.allowedPolicyMemberDomains", AllowedValues: []string{"C0abc"}}
+	policy := gcpapi.OrgPolicy{
+		Spec: &gcpapi.PolicySpec{
+			Rules: []gcpapi.PolicyRule{
+				{Values: &gcpapi.PolicyValues{AllowedValues: []string{"C0abc", "C0xyz"}}},
+			},
+		},
+	}
+
+	ok, reason := evaluateOrgPolicy(rule, policy)
+	if ok {
+		t.Fatalf("expected unexpected allowed value to fail evaluation")
+	}
+	if reason != "allows `C0xyz`, which is outside the baseline" {
+		t.Fatalf("unexpected reason: %s", reason)
+	}
+
+	if ok, _ := evaluateOrgPolicy(rule, gcpapi.OrgPolicy{}); ok {
+		t.Fatalf("expected empty policy to fail evaluation")
+	}
+}
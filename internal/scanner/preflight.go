@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+)
+
+const (
+	PreflightRun     = "run"
+	PreflightSkip    = "skip"
+	PreflightUnknown = "unknown"
+)
+
+// CheckPreflight says whether a check is expected to run against the scan
+// target and, when it is not, why.
+type CheckPreflight struct {
+	ID                 string   `json:"id"`
+	Status             string   `json:"status"`
+	Reason             string   `json:"reason,omitempty"`
+	MissingPermissions []string `json:"missing_permissions,omitempty"`
+}
+
+type Preflight struct {
+	Backend       string           `json:"backend"`
+	GCloudPath    string           `json:"gcloud_path,omitempty"`
+	GCloudVersion string           `json:"gcloud_version,omitempty"`
+	Account       string           `json:"account,omitempty"`
+	Target        string           `json:"target,omitempty"`
+	TargetExists  bool             `json:"target_exists"`
+	Problems      []string         `json:"problems,omitempty"`
+	Checks        []CheckPreflight `json:"checks"`
+}
+
+func (p Preflight) OK() bool {
+	return len(p.Problems) == 0
+}
+
+// Preflight verifies the local tooling and credentials and tests every
+// permission each selected check needs on the scan target, without running
+// any check.
+func (s *Scanner) Preflight(ctx context.Context) Preflight {
+	p := Preflight{Backend: s.opts.Backend}
+	blocked := ""
+
+	if s.opts.Backend != BackendREST {
+		path, err := s.runner.LookPath("gcloud")
+		if err != nil {
+			p.Problems = append(p.Problems, "gcloud not found in PATH")
+			blocked = "gcloud not found in PATH"
+		} else {
+			p.GCloudPath = path
+			p.GCloudVersion, p.Account = s.gcloudIdentity(ctx, &p)
+		}
+	}
+
+	scope := ScopeProject
+	if s.opts.Project == "" && (s.opts.Organization != "" || s.opts.Folder != "") {
+		scope = Scope(s.scanRoot().kind)
+	}
+	if s.opts.Project == "" && s.opts.Organization == "" && s.opts.Folder == "" {
+		p.Problems = append(p.Problems, "project not set; use --project, --organization or --folder")
+		if blocked == "" {
+			blocked = "no scan target"
+		}
+	} else {
+		p.Target = s.target()
+		if blocked == "" {
+			if err := s.describeTarget(ctx); err != nil {
+				p.Problems = append(p.Problems, fmt.Sprintf("%s not found or not accessible: %v", p.Target, err))
+				blocked = p.Target + " not accessible"
+			} else {
+				p.TargetExists = true
+			}
+		}
+	}
+
+	var api *gcpapi.Client
+	if blocked == "" {
+		var err error
+		if api, err = s.preflightClient(ctx); err != nil {
+			p.Problems = append(p.Problems, fmt.Sprintf("cannot obtain an access token: %v", err))
+			blocked = "no access token"
+		}
+	}
+
+	for _, c := range registry {
+		info := c.Info()
+		if !info.HasScope(scope) && !(scope != ScopeProject && info.HasScope(ScopeProject)) {
+			continue
+		}
+		p.Checks = append(p.Checks, s.preflightCheck(ctx, api, info, blocked, p.Target))
+	}
+	return p
+}
+
+func (s *Scanner) preflightCheck(ctx context.Context, api *gcpapi.Client, info CheckInfo, blocked, target string) CheckPreflight {
+	out := CheckPreflight{ID: info.ID, Status: PreflightRun}
+	switch {
+	case !s.selected(info):
+		out.Status, out.Reason = PreflightSkip, "not selected"
+	case info.GCloudOnly && s.opts.Backend == BackendREST:
+		out.Status, out.Reason = PreflightSkip, "not available with the rest backend"
+	case blocked != "":
+		out.Status, out.Reason = PreflightUnknown, blocked
+	case len(info.Permissions) > 0:
+		granted, err := api.TestIAMPermissions(ctx, target, info.Permissions)
+		if err != nil {
+			out.Status, out.Reason = PreflightUnknown, fmt.Sprintf("could not test permissions: %v", err)
+			break
+		}
+		for _, perm := range info.Permissions {
+			if !slices.Contains(granted, perm) {
+				out.MissingPermissions = append(out.MissingPermissions, perm)
+			}
+		}
+		if len(out.MissingPermissions) > 0 {
+			out.Status = PreflightSkip
+			out.Reason = "missing " + strings.Join(out.MissingPermissions, ", ")
+		}
+	}
+	if out.Status == PreflightRun && len(info.BillingPermissions) > 0 {
+		out.Status = PreflightUnknown
+		out.Reason = "cannot test " + strings.Join(info.BillingPermissions, ", ") + " on the billing account"
+	}
+	return out
+}
+
+func (s *Scanner) gcloudIdentity(ctx context.Context, p *Preflight) (string, string) {
+	var version map[string]any
+	if err := s.gcloudDecode(ctx, &version, "version"); err != nil {
+		p.Problems = append(p.Problems, fmt.Sprintf("gcloud version failed: %v", err))
+	}
+	var account string
+	if err := s.gcloudDecode(ctx, &account, "config", "get-value", "account"); err != nil || account == "" {
+		p.Problems = append(p.Problems, "no active gcloud account; run gcloud auth login")
+	}
+	return asString(version["Google Cloud SDK"]), account
+}
+
+func (s *Scanner) describeTarget(ctx context.Context) error {
+	if s.opts.Backend == BackendREST {
+		return nil
+	}
+	var args []string
+	switch {
+	case s.opts.Project != "":
+		args = []string{"projects", "describe", s.opts.Project}
+	case s.opts.Organization != "":
+		args = []string{"organizations", "describe", s.opts.Organization}
+	default:
+		args = []string{"resource-manager", "folders", "describe", s.opts.Folder}
+	}
+	_, err := s.gcloudOutput(ctx, args...)
+	return err
+}
+
+// preflightClient returns the REST client used for testIamPermissions. With
+// the gcloud backend it borrows the active gcloud account's access token.
+func (s *Scanner) preflightClient(ctx context.Context) (*gcpapi.Client, error) {
+	if s.opts.API != nil {
+		return s.opts.API, nil
+	}
+	cmdCtx, cancel := s.cmdCtx(ctx)
+	defer cancel()
+	out, err := s.runner.Run(cmdCtx, "gcloud", "auth", "print-access-token")
+	if err != nil {
+		return nil, err
+	}
+	api := gcpapi.New(gcpapi.StaticToken(strings.TrimSpace(string(out))))
+	api.Limiter = s.limiter
+	api.Backoff = s.opts.Backoff
+	return api, nil
+}
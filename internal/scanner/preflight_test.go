@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+)
+
+func TestPreflightReportsMissingPermissions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/projects/demo:testIamPermissions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Permissions []string `json:"permissions"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var granted []string
+		for _, p := range req.Permissions {
+			if p != "iam.serviceAccountKeys.list" {
+				granted = append(granted, p)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"permissions": granted})
+	}))
+	defer srv.Close()
+
+	api := gcpapi.New(gcpapi.StaticToken("t"))
+	api.Endpoints = gcpapi.SingleEndpoint(srv.URL)
+	runner := stubRunner{
+		"gcloud version --format=json":                  `{"Google Cloud SDK": "470.0.0"}`,
+		"gcloud config get-value account --format=json": `"auditor@example.com"`,
+		"gcloud projects describe demo --format=json":   `{"projectId": "demo"}`,
+	}
+	s := New(Options{Project: "demo", API: api, Runner: runner, SkipChecks: []string{"billing-budgets"}})
+
+	pre := s.Preflight(context.Background())
+	if !pre.OK() {
+		t.Fatalf("unexpected problems: %v", pre.Problems)
+	}
+	if pre.GCloudVersion != "470.0.0" || pre.Account != "auditor@example.com" || !pre.TargetExists {
+		t.Fatalf("unexpected environment: %+v", pre)
+	}
+
+	got := map[string]CheckPreflight{}
+	for _, c := range pre.Checks {
+		got[c.ID] = c
+	}
+	if _, ok := got["local-secrets"]; ok {
+		t.Fatalf("repo checks should not be listed: %+v", pre.Checks)
+	}
+	if got["api-keys"].Status != PreflightRun {
+		t.Fatalf("expected api-keys to run, got %+v", got["api-keys"])
+	}
+	sa := got["service-account-keys"]
+	if sa.Status != PreflightSkip || !reflect.DeepEqual(sa.MissingPermissions, []string{"iam.serviceAccountKeys.list"}) {
+		t.Fatalf("expected missing key list permission, got %+v", sa)
+	}
+	if b := got["billing-budgets"]; b.Status != PreflightSkip || b.Reason != "not selected" {
+		t.Fatalf("expected billing-budgets to be deselected, got %+v", b)
+	}
+}
+
+func TestPreflightCannotTestBillingAccountPermissions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Permissions []string `json:"permissions"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]any{"permissions": req.Permissions})
+	}))
+	defer srv.Close()
+
+	api := gcpapi.New(gcpapi.StaticToken("t"))
+	api.Endpoints = gcpapi.SingleEndpoint(srv.URL)
+	runner := stubRunner{
+		"gcloud version --format=json":                  `{"Google Cloud SDK": "470.0.0"}`,
+		"gcloud config get-value account --format=json": `"auditor@example.com"`,
+		"gcloud projects describe demo --format=json":   `{"projectId": "demo"}`,
+	}
+	s := New(Options{Project: "demo", API: api, Runner: runner, Checks: []string{"billing-budgets"}})
+
+	pre := s.Preflight(context.Background())
+	for _, c := range pre.Checks {
+		if c.ID != "billing-budgets" {
+			continue
+		}
+		if c.Status != PreflightUnknown || !strings.Contains(c.Reason, "billing.budgets.list") {
+			t.Fatalf("expected billing-budgets to be unknown for the billing account permission, got %+v", c)
+		}
+		return
+	}
+	t.Fatalf("billing-budgets missing from preflight: %+v", pre.Checks)
+}
+
+func TestPreflightWithoutGCloud(t *testing.T) {
+	s := New(Options{Project: "demo", Runner: missingGCloud{}})
+
+	pre := s.Preflight(context.Background())
+	if pre.OK() || pre.Problems[0] != "gcloud not found in PATH" {
+		t.Fatalf("expected missing gcloud problem, got %v", pre.Problems)
+	}
+	for _, c := range pre.Checks {
+		if c.Status != PreflightUnknown {
+			t.Fatalf("expected %s to be unknown, got %+v", c.ID, c)
+		}
+	}
+}
+
+type missingGCloud struct{ stubRunner }
+
+func (missingGCloud) LookPath(file string) (string, error) {
+	return "", errors.New(file + ": executable file not found in $PATH")
+}
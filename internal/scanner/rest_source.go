@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type restSource struct {
+	api *gcpapi.Client
+}
+
+func (r restSource) apiKeys(ctx context.Context, project string) ([]gcpapi.APIKey, error) {
+	return r.api.APIKeys(ctx, project)
+}
+
+func (r restSource) serviceAccounts(ctx context.Context, project string) ([]gcpapi.ServiceAccount, error) {
+	return r.api.ServiceAccounts(ctx, project)
+}
+
+func (r restSource) serviceAccountKeys(ctx context.Context, project, email string) ([]gcpapi.ServiceAccountKey, error) {
+	return r.api.ServiceAccountKeys(ctx, project, email)
+}
+
+func (r restSource) orgPolicy(ctx context.Context, constraint string, ref resourceRef, effective bool) (gcpapi.OrgPolicy, error) {
+	return r.api.OrgPolicy(ctx, ref.name(), constraint, effective)
+}
+
+func (r restSource) ancestors(ctx context.Context, project string) ([]resourceRef, error) {
+	list, err := r.api.Ancestry(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]resourceRef, 0, len(list))
+	for _, item := range list {
+		out = append(out, resourceRef{kind: asString(item["type"]), id: asString(item["id"])})
+	}
+	return out, nil
+}
+
+func (r restSource) iamPolicy(ctx context.Context, ref resourceRef) (map[string]any, error) {
+	return r.api.IAMPolicy(ctx, ref.name())
+}
+
+func (r restSource) contacts(ctx context.Context, project, category string) ([]gcpapi.Contact, error) {
+	return r.api.ComputeContacts(ctx, project, category)
+}
+
+func (r restSource) childProjects(ctx context.Context, parent resourceRef) ([]model.Project, error) {
+	list, err := r.api.Projects(ctx, parent.name())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Project, 0, len(list))
+	for _, p := range list {
+		out = append(out, model.Project{
+			ID:     asString(p["projectId"]),
+			Number: strings.TrimPrefix(asString(p["name"]), "projects/"),
+			State:  asString(p["state"]),
+			Labels: stringMap(p["labels"]),
+		})
+	}
+	return out, nil
+}
+
+func (r restSource) childFolders(ctx context.Context, parent resourceRef) ([]resourceRef, error) {
+	list, err := r.api.Folders(ctx, parent.name())
+	if err != nil {
+		return nil, err
+	}
+	var out []resourceRef
+	for _, f := range list {
+		if id := strings.TrimPrefix(asString(f["name"]), "folders/"); id != "" {
+			out = append(out, resourceRef{kind: "folder", id: id})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+func TestScanWithRESTBackend(t *testing.T) {
+	const constraint = "constraints/iam.disableServiceAccountKeyCreation"
+	responses := map[string]string{
+		"GET /v2/projects/demo/locations/global/keys":                                            `{"keys": [{"name": "projects/1/locations/global/keys/k1", "displayName": "maps"}]}`,
+		"GET /v1/projects/demo/serviceAccounts":                                                  `{"accounts": [{"email": "ci@demo.iam.gserviceaccount.com"}]}`,
+		"GET /v1/projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys":             `{"keys": [{"name": "projects/demo/serviceAccounts/ci@demo.iam.gserviceaccount.com/keys/abc", "validAfterTime": "2020-01-01T00:00:00Z"}]}`,
+		"GET /v2/projects/demo/policies/iam.disableServiceAccountKeyCreation:getEffectivePolicy": `{"spec": {"rules": [{"enforce": true}]}}`,
+		"GET /v1/projects/demo/contacts:compute":                                                 `{"contacts": [{"name": "organizations/42/contacts/1", "email": "sec@example.com"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": 404, "status": "NOT_FOUND"}}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	api := gcpapi.New(gcpapi.StaticToken("t"))
+	api.Endpoints = gcpapi.SingleEndpoint(srv.URL)
+	rule := withOrgPolicyDefaults(model.OrgPolicyRule{Constraint: constraint, Enforced: boolPtr(true)})
+	s := New(Options{
+		Backend:           BackendREST,
+		API:               api,
+		Project:           "demo",
+		Runner:            stubRunner{},
+		OrgPolicyBaseline: []model.OrgPolicyRule{rule},
+	})
+
+	result, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	ids := map[string]bool{}
+	for _, f := range result.Findings {
+		ids[f.ID] = true
+	}
+	for _, want := range []string{"gcp.api_key.unrestricted", "gcp.sa_key.no_expiry", "gcp.sa_key.stale_review"} {
+		if !ids[want] {
+			t.Errorf("missing finding %s in %+v", want, result.Findings)
+		}
+	}
+	if ids["gcp.essential_contacts.none"] || ids[rule.ID] {
+		t.Errorf("unexpected findings: %+v", result.Findings)
+	}
+
+	skipped := 0
+	for _, note := range result.Notes {
+		if strings.Contains(note, "not available with the rest backend") {
+			skipped++
+		}
+	}
+	if skipped != 4 {
+		t.Fatalf("expected gcloud-only checks to be skipped, notes: %v", result.Notes)
+	}
+}
@@ -2,24 +2,50 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Andrei-Barwood/gcpsec/internal/execx"
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
 	"github.com/Andrei-Barwood/gcpsec/internal/model"
+	"github.com/Andrei-Barwood/gcpsec/internal/ratelimit"
+)
+
+const (
+	BackendGCloud = "gcloud"
+	BackendREST   = "rest"
 )
 
 type Options struct {
-	Project      string
-	RepoPath     string
-	InactiveDays int
-	Runner       execx.Runner
-	Timeout      time.Duration
+	Backend               string
+	API                   *gcpapi.Client
+	Project               string
+	Organization          string
+	Folder                string
+	Parallelism           int
+	Concurrency           int
+	RateLimit             float64
+	Backoff               ratelimit.Backoff
+	Checks                []string
+	SkipChecks            []string
+	Selection             model.ProjectSelection
+	AssetExport           string
+	RepoPath              string
+	InactiveDays          int
+	AllowedContactDomains []string
+	OrgPolicyBaseline     []model.OrgPolicyRule
+	Runner                execx.Runner
+	Timeout               time.Duration
 }
 
 type Scanner struct {
-	opts   Options
-	runner execx.Runner
+	opts        Options
+	runner      execx.Runner
+	src         source
+	limiter     *ratelimit.Limiter
+	orgPolicies []model.OrgPolicyRule
 }
 
 func New(opts Options) *Scanner {
@@ -29,59 +55,275 @@ func New(opts Options) *Scanner {
 	if opts.Timeout <= 0 {
 		opts.Timeout = 30 * time.Second
 	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = 4
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.Backoff.Attempts == 0 {
+		opts.Backoff = ratelimit.DefaultBackoff()
+	}
 	if opts.Runner == nil {
 		opts.Runner = execx.OSRunner{}
 	}
+	if opts.Backend == "" {
+		opts.Backend = BackendGCloud
+	}
+	limiter := ratelimit.New(opts.RateLimit, opts.Concurrency)
+	if opts.Backend == BackendREST {
+		if opts.API == nil {
+			opts.API = gcpapi.New(gcpapi.DefaultTokenSource(nil))
+		}
+		if opts.API.Limiter == nil {
+			opts.API.Limiter = limiter
+		}
+		opts.API.Backoff = opts.Backoff
+	}
 
-	return &Scanner{opts: opts, runner: opts.Runner}
+	orgPolicies := opts.OrgPolicyBaseline
+	if orgPolicies == nil {
+		orgPolicies, _ = MergeOrgPolicyBaseline(DefaultOrgPolicyBaseline(), nil)
+	}
+
+	s := &Scanner{opts: opts, runner: opts.Runner, limiter: limiter, orgPolicies: orgPolicies}
+	if opts.Backend == BackendREST {
+		s.src = restSource{api: opts.API}
+	} else {
+		s.src = gcloudSource{s: s}
+	}
+	return s
 }
 
 func (s *Scanner) Scan(ctx context.Context) (model.ScanResult, error) {
+	result, err := s.scan(ctx)
+	for i := range result.Findings {
+		result.Findings[i].Fingerprint = model.Fingerprint(result.Findings[i])
+	}
+	return result, err
+}
+
+func (s *Scanner) scan(ctx context.Context) (model.ScanResult, error) {
 	result := model.ScanResult{
-		GeneratedAt: time.Now().UTC(),
-		Project:     s.opts.Project,
-		Repo:        s.opts.RepoPath,
+		SchemaVersion: model.SchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Findings:      []model.Finding{},
+		Project:       s.opts.Project,
+		Repo:          s.opts.RepoPath,
 	}
 
-	localFindings, err := s.scanLocalRepo(ctx)
-	if err != nil {
-		result.Notes = append(result.Notes, fmt.Sprintf("local repo scan failed: %v", err))
-	} else {
-		result.Findings = append(result.Findings, localFindings...)
+	if s.opts.RepoPath != "" {
+		s.runChecks(ctx, &result, s.checksFor(ScopeRepo))
 	}
 
-	if s.opts.Project == "" {
+	if s.opts.AssetExport != "" {
+		if err := s.scanAssetExport(ctx, &result); err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+
+	if s.opts.Organization == "" && s.opts.Folder == "" && s.opts.Project == "" {
 		result.Notes = append(result.Notes, "project not set; skipping gcloud-based checks")
+		s.skipRemoteChecks(&result, reasonNoTarget, "no project, folder or organization set")
 		return result, nil
 	}
 
-	if _, err := s.runner.LookPath("gcloud"); err != nil {
-		result.Notes = append(result.Notes, "gcloud not found in PATH; skipping gcloud-based checks")
+	if s.opts.Backend != BackendREST {
+		if _, err := s.runner.LookPath("gcloud"); err != nil {
+			result.Notes = append(result.Notes, "gcloud not found in PATH; skipping gcloud-based checks")
+			s.skipRemoteChecks(&result, reasonGCloudNotFound, "gcloud not found in PATH")
+			return result, nil
+		}
+	}
+
+	if s.opts.Organization != "" || s.opts.Folder != "" {
+		s.scanHierarchy(ctx, &result)
 		return result, nil
 	}
 
-	checks := []struct {
-		name string
-		run  func(context.Context) ([]model.Finding, error)
-	}{
-		{name: "api key restrictions", run: s.scanAPIKeys},
-		{name: "service account keys", run: s.scanServiceAccountKeys},
-		{name: "org policies", run: s.scanOrgPolicies},
-		{name: "essential contacts", run: s.scanEssentialContacts},
+	s.runProjectChecks(ctx, &result, s.checksFor(ScopeProject))
+
+	return result, nil
+}
+
+// skipRemoteChecks records every selected check that needs Google Cloud as
+// skipped, so a scan that could not reach it is not mistaken for a clean one.
+func (s *Scanner) skipRemoteChecks(result *model.ScanResult, code, reason string) {
+	for _, c := range registry {
+		info := c.Info()
+		if !s.selected(info) || !info.Remote() {
+			continue
+		}
+		result.Executions = append(result.Executions, model.CheckExecution{
+			Check:      info.ID,
+			Project:    s.opts.Project,
+			Target:     s.target(),
+			Status:     model.CheckSkipped,
+			Reason:     code,
+			Message:    reason,
+			FindingIDs: s.findingIDs(c),
+		})
+	}
+}
+
+func (s *Scanner) runProjectChecks(ctx context.Context, result *model.ScanResult, checks []check) {
+	start := len(result.Findings)
+	s.runChecks(ctx, result, checks)
+	for i := start; i < len(result.Findings); i++ {
+		result.Findings[i].Project = s.opts.Project
+	}
+}
+
+// runChecks runs checks concurrently and appends their findings, notes,
+// resource errors and execution records to result in check order.
+func (s *Scanner) runChecks(ctx context.Context, result *model.ScanResult, checks []check) {
+	type outcome struct {
+		findings  []model.Finding
+		err       error
+		duration  time.Duration
+		resources int
 	}
+	outcomes := make([]outcome, len(checks))
+	forEach(s.opts.Concurrency, len(checks), func(i int) {
+		checkCtx, stats := withExecStats(ctx)
+		start := time.Now()
+		found, err := checks[i].run(checkCtx)
+		outcomes[i] = outcome{
+			findings:  found,
+			err:       err,
+			duration:  time.Since(start),
+			resources: int(stats.resources.Load()),
+		}
+	})
+
+	for i, check := range checks {
+		runErr := outcomes[i].err
+		exec := model.CheckExecution{
+			Check:              check.name,
+			Project:            s.opts.Project,
+			Target:             s.target(),
+			DurationMillis:     outcomes[i].duration.Milliseconds(),
+			ResourcesEvaluated: outcomes[i].resources,
+			FindingIDs:         check.findingIDs,
+		}
 
-	for _, check := range checks {
-		findings, runErr := check.run(ctx)
-		if runErr != nil {
+		var skip *skipError
+		var partial *partialError
+		switch {
+		case errors.As(runErr, &skip):
+			exec.Status, exec.Reason, exec.Message = model.CheckSkipped, skip.code, skip.reason
+			result.Notes = append(result.Notes, fmt.Sprintf("%s check skipped: %s", check.name, skip.reason))
+		case gcpapi.IsSchemaError(runErr):
+			exec.Status, exec.Reason, exec.Message = model.CheckSkipped, reasonSchemaMismatch, runErr.Error()
+			exec.ErrorCategory = categorySchemaMismatch
+			result.Notes = append(result.Notes, fmt.Sprintf("%s check skipped: %v", check.name, runErr))
+		case errors.As(runErr, &partial):
+			for _, e := range partial.errors {
+				e.Check = check.name
+				e.Project = s.opts.Project
+				result.Errors = append(result.Errors, e)
+			}
+			exec.Status, exec.Reason, exec.Message = model.CheckErrored, reasonPartialResults, runErr.Error()
+			exec.ErrorCategory = partialCategory(partial)
+			exec.Findings = len(outcomes[i].findings)
+			result.Notes = append(result.Notes, fmt.Sprintf("%s check incomplete: %v", check.name, runErr))
+			result.Findings = append(result.Findings, outcomes[i].findings...)
+		case runErr != nil:
+			exec.Status, exec.Reason, exec.Message = model.CheckErrored, reasonCheckError, runErr.Error()
+			exec.ErrorCategory = errorCategory(runErr)
 			result.Notes = append(result.Notes, fmt.Sprintf("%s check failed: %v", check.name, runErr))
-			continue
+		default:
+			exec.Findings = len(outcomes[i].findings)
+			exec.Status, exec.Reason = executionStatus(exec.Findings)
+			result.Findings = append(result.Findings, outcomes[i].findings...)
 		}
-		result.Findings = append(result.Findings, findings...)
+		result.Executions = append(result.Executions, exec)
 	}
+}
 
-	return result, nil
+func forEach(limit, n int, fn func(i int)) {
+	if limit <= 0 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func (s *Scanner) forProject(project string) *Scanner {
+	child := *s
+	child.opts.Project = project
+	return &child
+}
+
+func (s *Scanner) forRoot(root resourceRef) *Scanner {
+	child := *s
+	child.opts.Project = ""
+	child.opts.Organization, child.opts.Folder = "", ""
+	if root.kind == "organization" {
+		child.opts.Organization = root.id
+	} else {
+		child.opts.Folder = root.id
+	}
+	return &child
 }
 
 func (s *Scanner) cmdCtx(parent context.Context) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(parent, s.opts.Timeout)
 }
+
+type skipError struct {
+	code   string
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+func skipCheck(code, format string, args ...any) error {
+	return &skipError{code: code, reason: fmt.Sprintf(format, args...)}
+}
+
+// partialError collects per-resource failures so a check can still return
+// the findings for every resource it did evaluate.
+type partialError struct {
+	errors []model.ResourceError
+	causes []error
+}
+
+func (e *partialError) add(resource string, err error) {
+	e.errors = append(e.errors, model.ResourceError{Resource: resource, Message: err.Error()})
+	e.causes = append(e.causes, err)
+}
+
+// err returns nil when every resource was evaluated. A schema mismatch is
+// returned as is so the whole check is skipped rather than half-trusted.
+func (e *partialError) err() error {
+	if len(e.errors) == 0 {
+		return nil
+	}
+	for _, cause := range e.causes {
+		if gcpapi.IsSchemaError(cause) {
+			return cause
+		}
+	}
+	return e
+}
+
+func (e *partialError) Error() string {
+	if len(e.errors) == 1 {
+		return fmt.Sprintf("%s could not be evaluated: %s", e.errors[0].Resource, e.errors[0].Message)
+	}
+	return fmt.Sprintf("%d resources could not be evaluated", len(e.errors))
+}
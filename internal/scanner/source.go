@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Andrei-Barwood/gcpsec/internal/gcpapi"
+	"github.com/Andrei-Barwood/gcpsec/internal/model"
+)
+
+type source interface {
+	apiKeys(ctx context.Context, project string) ([]gcpapi.APIKey, error)
+	serviceAccounts(ctx context.Context, project string) ([]gcpapi.ServiceAccount, error)
+	serviceAccountKeys(ctx context.Context, project, email string) ([]gcpapi.ServiceAccountKey, error)
+	orgPolicy(ctx context.Context, constraint string, ref resourceRef, effective bool) (gcpapi.OrgPolicy, error)
+	ancestors(ctx context.Context, project string) ([]resourceRef, error)
+	iamPolicy(ctx context.Context, ref resourceRef) (map[string]any, error)
+	contacts(ctx context.Context, project, category string) ([]gcpapi.Contact, error)
+	childProjects(ctx context.Context, parent resourceRef) ([]model.Project, error)
+	childFolders(ctx context.Context, parent resourceRef) ([]resourceRef, error)
+}
+
+type gcloudSource struct {
+	s *Scanner
+}
+
+func (g gcloudSource) apiKeys(ctx context.Context, project string) ([]gcpapi.APIKey, error) {
+	var keys []gcpapi.APIKey
+	err := g.s.gcloudDecode(ctx, &keys, "services", "api-keys", "list", "--project", project)
+	return keys, err
+}
+
+func (g gcloudSource) serviceAccounts(ctx context.Context, project string) ([]gcpapi.ServiceAccount, error) {
+	var accounts []gcpapi.ServiceAccount
+	err := g.s.gcloudDecode(ctx, &accounts, "iam", "service-accounts", "list", "--project", project)
+	return accounts, err
+}
+
+func (g gcloudSource) serviceAccountKeys(ctx context.Context, project, email string) ([]gcpapi.ServiceAccountKey, error) {
+	var keys []gcpapi.ServiceAccountKey
+	err := g.s.gcloudDecode(
+		ctx,
+		&keys,
+		"iam", "service-accounts", "keys", "list",
+		"--iam-account", email,
+		"--managed-by", "user",
+		"--project", project,
+	)
+	return keys, err
+}
+
+func (g gcloudSource) orgPolicy(ctx context.Context, constraint string, ref resourceRef, effective bool) (gcpapi.OrgPolicy, error) {
+	args := append([]string{"org-policies", "describe", constraint}, ref.flag()...)
+	if effective {
+		args = append(args, "--effective")
+	}
+	var policy gcpapi.OrgPolicy
+	if err := g.s.gcloudDecode(ctx, &policy, args...); err != nil {
+		if isNotFound(err) {
+			return gcpapi.OrgPolicy{}, nil
+		}
+		return gcpapi.OrgPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (g gcloudSource) ancestors(ctx context.Context, project string) ([]resourceRef, error) {
+	list, err := g.s.gcloudJSON(ctx, "projects", "get-ancestors", project)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]resourceRef, 0, len(list))
+	for _, item := range list {
+		out = append(out, resourceRef{kind: asString(item["type"]), id: asString(item["id"])})
+	}
+	return out, nil
+}
+
+func (g gcloudSource) iamPolicy(ctx context.Context, ref resourceRef) (map[string]any, error) {
+	var args []string
+	switch ref.kind {
+	case "organization":
+		args = []string{"organizations", "get-iam-policy", ref.id}
+	case "folder":
+		args = []string{"resource-manager", "folders", "get-iam-policy", ref.id}
+	default:
+		args = []string{"projects", "get-iam-policy", ref.id}
+	}
+	list, err := g.s.gcloudJSON(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return map[string]any{}, nil
+	}
+	return list[0], nil
+}
+
+func (g gcloudSource) contacts(ctx context.Context, project, category string) ([]gcpapi.Contact, error) {
+	var contacts []gcpapi.Contact
+	err := g.s.gcloudDecode(
+		ctx,
+		&contacts,
+		"essential-contacts", "compute",
+		"--project", project,
+		"--notification-categories", category,
+	)
+	return contacts, err
+}
+
+func (g gcloudSource) childProjects(ctx context.Context, parent resourceRef) ([]model.Project, error) {
+	list, err := g.s.gcloudJSON(
+		ctx,
+		"projects", "list",
+		"--filter", fmt.Sprintf("parent.type=%s AND parent.id=%s", parent.kind, parent.id),
+	)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Project, 0, len(list))
+	for _, p := range list {
+		out = append(out, model.Project{
+			ID:     asString(p["projectId"]),
+			Number: asString(p["projectNumber"]),
+			State:  asString(p["lifecycleState"]),
+			Labels: stringMap(p["labels"]),
+		})
+	}
+	return out, nil
+}
+
+func (g gcloudSource) childFolders(ctx context.Context, parent resourceRef) ([]resourceRef, error) {
+	list, err := g.s.gcloudJSON(ctx, append([]string{"resource-manager", "folders", "list"}, parent.flag()...)...)
+	if err != nil {
+		return nil, err
+	}
+	var out []resourceRef
+	for _, f := range list {
+		if id := strings.TrimPrefix(asString(f["name"]), "folders/"); id != "" {
+			out = append(out, resourceRef{kind: "folder", id: id})
+		}
+	}
+	return out, nil
+}